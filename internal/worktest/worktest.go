@@ -0,0 +1,31 @@
+// Package worktest 企业微信接口单元测试的公共方法，配合gock模拟接口
+package worktest
+
+import (
+	"gopkg.in/h2non/gock.v1"
+
+	"github.com/silenceper/wechat/v2/cache"
+	"github.com/silenceper/wechat/v2/credential"
+	"github.com/silenceper/wechat/v2/work/config"
+	"github.com/silenceper/wechat/v2/work/context"
+)
+
+// MockAccessToken 模拟一次获取access_token，获取后缓存在内存中
+func MockAccessToken() {
+	gock.New("https://qyapi.weixin.qq.com").Get("/cgi-bin/gettoken").Reply(200).JSON(map[string]interface{}{"access_token": "ACCESS_TOKEN", "expires_in": 7200})
+}
+
+// NewConfig 返回使用内存缓存的企业配置
+func NewConfig() *config.Config {
+	return &config.Config{CorpID: "corp", CorpSecret: "secret", Cache: cache.NewMemory()}
+}
+
+// NewContext 创建使用内存缓存的上下文，并模拟获取access_token
+func NewContext() *context.Context {
+	MockAccessToken()
+	cfg := NewConfig()
+	return &context.Context{
+		Config:            cfg,
+		AccessTokenHandle: credential.NewWorkAccessToken(cfg.CorpID, cfg.CorpSecret, credential.CacheKeyWorkPrefix, cfg.Cache),
+	}
+}
@@ -0,0 +1,203 @@
+package addresslist
+
+import (
+	stdcontext "context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/northseadl/wechat/v2/work/material"
+	"github.com/silenceper/wechat/v2/util"
+)
+
+const (
+	// batchSyncUserURL 增量更新成员
+	batchSyncUserURL = "https://qyapi.weixin.qq.com/cgi-bin/batch/syncuser?access_token=%s"
+	// batchReplaceUserURL 全量覆盖成员
+	batchReplaceUserURL = "https://qyapi.weixin.qq.com/cgi-bin/batch/replaceuser?access_token=%s"
+	// batchReplacePartyURL 全量覆盖部门
+	batchReplacePartyURL = "https://qyapi.weixin.qq.com/cgi-bin/batch/replaceparty?access_token=%s"
+	// batchGetResultURL 获取异步任务结果
+	batchGetResultURL = "https://qyapi.weixin.qq.com/cgi-bin/batch/getresult?access_token=%s&jobid=%s"
+)
+
+// 异步任务类型
+const (
+	// BatchJobSyncUser 增量更新成员
+	BatchJobSyncUser = "sync_user"
+	// BatchJobReplaceUser 全量覆盖成员
+	BatchJobReplaceUser = "replace_user"
+	// BatchJobReplaceParty 全量覆盖部门
+	BatchJobReplaceParty = "replace_party"
+)
+
+// 异步任务状态
+const (
+	// BatchJobStatusPending 任务开始
+	BatchJobStatusPending = 1
+	// BatchJobStatusRunning 任务进行中
+	BatchJobStatusRunning = 2
+	// BatchJobStatusFinished 任务已完成
+	BatchJobStatusFinished = 3
+)
+
+// defaultBatchPollInterval 默认的异步任务结果轮询间隔
+const defaultBatchPollInterval = 3 * time.Second
+
+type (
+	// BatchCallback 异步任务完成后的回调配置
+	BatchCallback struct {
+		URL            string `json:"url,omitempty"`
+		Token          string `json:"token,omitempty"`
+		EncodingAESKey string `json:"encodingaeskey,omitempty"`
+	}
+	// BatchUserRequest 增量更新成员/全量覆盖成员请求
+	BatchUserRequest struct {
+		MediaID  string         `json:"media_id"`            // 上传的csv文件的media_id
+		ToInvite *bool          `json:"to_invite,omitempty"` // 是否邀请新建的成员使用企业微信，默认为true
+		Callback *BatchCallback `json:"callback,omitempty"`
+	}
+	// BatchPartyRequest 全量覆盖部门请求
+	BatchPartyRequest struct {
+		MediaID  string         `json:"media_id"`
+		Callback *BatchCallback `json:"callback,omitempty"`
+	}
+	// BatchJobResponse 异步任务提交响应
+	BatchJobResponse struct {
+		util.CommonError
+		JobID string `json:"jobid"`
+	}
+)
+
+// BatchSyncUser 增量更新成员
+// see https://developer.work.weixin.qq.com/document/path/90980
+func (r *Client) BatchSyncUser(req *BatchUserRequest) (string, error) {
+	return r.submitBatchJob(batchSyncUserURL, req, "BatchSyncUser")
+}
+
+// BatchReplaceUser 全量覆盖成员
+// see https://developer.work.weixin.qq.com/document/path/90981
+func (r *Client) BatchReplaceUser(req *BatchUserRequest) (string, error) {
+	return r.submitBatchJob(batchReplaceUserURL, req, "BatchReplaceUser")
+}
+
+// BatchReplaceParty 全量覆盖部门
+// see https://developer.work.weixin.qq.com/document/path/90982
+func (r *Client) BatchReplaceParty(req *BatchPartyRequest) (string, error) {
+	return r.submitBatchJob(batchReplacePartyURL, req, "BatchReplaceParty")
+}
+
+// submitBatchJob 提交异步任务并返回jobid
+func (r *Client) submitBatchJob(urlFormat string, req interface{}, apiName string) (string, error) {
+	var (
+		accessToken string
+		err         error
+	)
+	if accessToken, err = r.GetAccessToken(); err != nil {
+		return "", err
+	}
+	var response []byte
+	if response, err = util.PostJSON(fmt.Sprintf(urlFormat, accessToken), req); err != nil {
+		return "", err
+	}
+	result := &BatchJobResponse{}
+	err = util.DecodeWithError(response, result, apiName)
+	return result.JobID, err
+}
+
+type (
+	// BatchGetResultResponse 获取异步任务结果响应
+	BatchGetResultResponse struct {
+		util.CommonError
+		Status     int               `json:"status"` // 任务状态，整型，1表示任务开始，2表示任务进行中，3表示任务已完成
+		Type       string            `json:"type"`   // 操作类型，sync_user、replace_user、replace_party
+		Total      int               `json:"total"`
+		Percentage int               `json:"percentage"`
+		Result     []BatchJobDetails `json:"result"` // 详细的处理结果，仅任务已完成时返回
+	}
+	// BatchJobDetails 异步任务中每一条记录的处理结果，成员任务返回UserID，部门任务返回Action与PartyID
+	BatchJobDetails struct {
+		util.CommonError
+		UserID  string `json:"userid"`
+		Action  int    `json:"action"` // 操作类型，1表示新建部门，2表示更改部门，3表示删除部门，4表示部门无变化
+		PartyID int    `json:"partyid"`
+	}
+)
+
+// BatchGetResult 获取异步任务结果
+// see https://developer.work.weixin.qq.com/document/path/90983
+func (r *Client) BatchGetResult(jobID string) (*BatchGetResultResponse, error) {
+	var (
+		accessToken string
+		err         error
+	)
+	if accessToken, err = r.GetAccessToken(); err != nil {
+		return nil, err
+	}
+	var response []byte
+	if response, err = util.HTTPGet(fmt.Sprintf(batchGetResultURL, accessToken, jobID)); err != nil {
+		return nil, err
+	}
+	result := &BatchGetResultResponse{}
+	err = util.DecodeWithError(response, result, "BatchGetResult")
+	return result, err
+}
+
+// WaitBatchJob 轮询异步任务结果直至任务完成或ctx结束，interval不大于0时使用默认轮询间隔
+func (r *Client) WaitBatchJob(ctx stdcontext.Context, jobID string, interval time.Duration) (*BatchGetResultResponse, error) {
+	if interval <= 0 {
+		interval = defaultBatchPollInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		result, err := r.BatchGetResult(jobID)
+		if err != nil {
+			return nil, err
+		}
+		if result.Status == BatchJobStatusFinished {
+			return result, nil
+		}
+		select {
+		case <-ctx.Done():
+			return result, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// BatchImportOptions 通过CSV文件导入通讯录的选项
+type BatchImportOptions struct {
+	ToInvite     *bool          // 是否邀请新建的成员使用企业微信，仅成员任务有效
+	Callback     *BatchCallback // 任务完成后的回调配置
+	PollInterval time.Duration  // 轮询任务结果的间隔，默认3秒
+}
+
+// BatchImport 将CSV文件上传为临时素材，提交jobType对应的异步任务，并等待任务完成后返回结果
+// jobType 可选 BatchJobSyncUser、BatchJobReplaceUser、BatchJobReplaceParty
+func (r *Client) BatchImport(ctx stdcontext.Context, jobType, filename string, reader io.Reader, opts *BatchImportOptions) (*BatchGetResultResponse, error) {
+	if jobType != BatchJobSyncUser && jobType != BatchJobReplaceUser && jobType != BatchJobReplaceParty {
+		return nil, fmt.Errorf("unsupported batch job type: %s", jobType)
+	}
+	if opts == nil {
+		opts = &BatchImportOptions{}
+	}
+	media, err := material.NewClient(r.Context).UploadTempFileFromReader(filename, "file", reader)
+	if err != nil {
+		return nil, err
+	}
+
+	var jobID string
+	switch jobType {
+	case BatchJobSyncUser:
+		jobID, err = r.BatchSyncUser(&BatchUserRequest{MediaID: media.MediaID, ToInvite: opts.ToInvite, Callback: opts.Callback})
+	case BatchJobReplaceUser:
+		jobID, err = r.BatchReplaceUser(&BatchUserRequest{MediaID: media.MediaID, ToInvite: opts.ToInvite, Callback: opts.Callback})
+	case BatchJobReplaceParty:
+		jobID, err = r.BatchReplaceParty(&BatchPartyRequest{MediaID: media.MediaID, Callback: opts.Callback})
+	}
+	if err != nil {
+		return nil, err
+	}
+	return r.WaitBatchJob(ctx, jobID, opts.PollInterval)
+}
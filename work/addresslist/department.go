@@ -9,6 +9,10 @@ import (
 const (
 	// departmentCreateURL 创建部门
 	departmentCreateURL = "https://qyapi.weixin.qq.com/cgi-bin/department/create?access_token=%s"
+	// departmentUpdateURL 更新部门
+	departmentUpdateURL = "https://qyapi.weixin.qq.com/cgi-bin/department/update?access_token=%s"
+	// departmentDeleteURL 删除部门
+	departmentDeleteURL = "https://qyapi.weixin.qq.com/cgi-bin/department/delete?access_token=%s&id=%d"
 	// departmentSimpleListURL 获取子部门ID列表
 	departmentSimpleListURL = "https://qyapi.weixin.qq.com/cgi-bin/department/simplelist?access_token=%s&id=%d"
 	// departmentListURL 获取部门列表
@@ -85,6 +89,49 @@ func (r *Client) DepartmentCreate(req *DepartmentCreateRequest) (*DepartmentCrea
 	return result, err
 }
 
+// DepartmentUpdateRequest 更新部门数据请求，除id外未填写的字段不会被修改
+type DepartmentUpdateRequest struct {
+	ID       int    `json:"id"`
+	Name     string `json:"name,omitempty"`
+	NameEn   string `json:"name_en,omitempty"`
+	ParentID int    `json:"parentid,omitempty"`
+	Order    *int   `json:"order,omitempty"` // 在父部门中的次序值，为nil时不修改，可设置为0
+}
+
+// DepartmentUpdate 更新部门
+// see https://developer.work.weixin.qq.com/document/path/90206
+func (r *Client) DepartmentUpdate(req *DepartmentUpdateRequest) error {
+	var (
+		accessToken string
+		err         error
+	)
+	if accessToken, err = r.GetAccessToken(); err != nil {
+		return err
+	}
+	var response []byte
+	if response, err = util.PostJSON(fmt.Sprintf(departmentUpdateURL, accessToken), req); err != nil {
+		return err
+	}
+	return util.DecodeWithCommonError(response, "DepartmentUpdate")
+}
+
+// DepartmentDelete 删除部门，不能删除根部门以及含有子部门、成员的部门
+// see https://developer.work.weixin.qq.com/document/path/90207
+func (r *Client) DepartmentDelete(departmentID int) error {
+	var (
+		accessToken string
+		err         error
+	)
+	if accessToken, err = r.GetAccessToken(); err != nil {
+		return err
+	}
+	var response []byte
+	if response, err = util.HTTPGet(fmt.Sprintf(departmentDeleteURL, accessToken, departmentID)); err != nil {
+		return err
+	}
+	return util.DecodeWithCommonError(response, "DepartmentDelete")
+}
+
 // DepartmentSimpleList 获取子部门ID列表
 // see https://developer.work.weixin.qq.com/document/path/95350
 func (r *Client) DepartmentSimpleList(departmentID int) ([]*DepartmentID, error) {
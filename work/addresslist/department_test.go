@@ -0,0 +1,27 @@
+package addresslist
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDepartmentUpdateRequestOrder(t *testing.T) {
+	zero := 0
+	tests := []struct {
+		name string
+		req  DepartmentUpdateRequest
+		want string
+	}{
+		{"order not set", DepartmentUpdateRequest{ID: 2, Name: "sales"}, `{"id":2,"name":"sales"}`},
+		{"order set to zero", DepartmentUpdateRequest{ID: 2, Order: &zero}, `{"id":2,"order":0}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(tt.req)
+			assert.Nil(t, err)
+			assert.JSONEq(t, tt.want, string(data))
+		})
+	}
+}
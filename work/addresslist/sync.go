@@ -0,0 +1,473 @@
+package addresslist
+
+import (
+	stdcontext "context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// rootDepartmentID 根部门ID，同步时不会被删除
+const rootDepartmentID = 1
+
+// SyncActionType 同步操作类型
+type SyncActionType string
+
+const (
+	// SyncCreateDepartment 创建部门
+	SyncCreateDepartment SyncActionType = "create_department"
+	// SyncUpdateDepartment 更新部门
+	SyncUpdateDepartment SyncActionType = "update_department"
+	// SyncDeleteDepartment 删除部门
+	SyncDeleteDepartment SyncActionType = "delete_department"
+	// SyncCreateUser 创建成员
+	SyncCreateUser SyncActionType = "create_user"
+	// SyncUpdateUser 更新成员
+	SyncUpdateUser SyncActionType = "update_user"
+	// SyncDeleteUser 删除成员
+	SyncDeleteUser SyncActionType = "delete_user"
+	// SyncCreateTag 创建标签
+	SyncCreateTag SyncActionType = "create_tag"
+	// SyncUpdateTag 更新标签名字
+	SyncUpdateTag SyncActionType = "update_tag"
+	// SyncDeleteTag 删除标签
+	SyncDeleteTag SyncActionType = "delete_tag"
+	// SyncAddTagUsers 增加标签成员
+	SyncAddTagUsers SyncActionType = "add_tag_users"
+	// SyncDelTagUsers 删除标签成员
+	SyncDelTagUsers SyncActionType = "del_tag_users"
+)
+
+type (
+	// OrgTree 通讯录快照，既用于描述期望状态，也用于描述企业微信中的当前状态
+	OrgTree struct {
+		Departments []*SyncDepartment
+		Users       []*SyncUser
+		Tags        []*SyncTag
+	}
+	// SyncDepartment 部门，期望状态中的部门必须指定ID，以便子部门和成员引用
+	SyncDepartment struct {
+		ID       int
+		ParentID int
+		Name     string
+		NameEn   string // 为空时不比较
+		Order    int    // 为0时不比较
+	}
+	// SyncUser 成员，仅比较Name与Department，其余字段只在创建成员时使用
+	SyncUser struct {
+		UserID         string
+		Name           string
+		Department     []int
+		MainDepartment int
+		Mobile         string
+		Email          string
+		Position       string
+	}
+	// SyncTag 标签，TagID为0时按名称匹配已有标签
+	SyncTag struct {
+		TagID    int
+		TagName  string
+		UserList []string
+	}
+)
+
+// SyncAction 一次同步操作
+type SyncAction struct {
+	Type       SyncActionType
+	Department *SyncDepartment
+	User       *SyncUser
+	Tag        *SyncTag
+	UserIDList []string // 增加/删除标签成员时的成员列表
+	Err        error    // 执行失败的原因，DryRun时恒为nil
+}
+
+// String 输出操作描述，用于DryRun时展示同步计划
+func (a *SyncAction) String() string {
+	switch a.Type {
+	case SyncCreateDepartment, SyncUpdateDepartment, SyncDeleteDepartment:
+		return fmt.Sprintf("%s id=%d parentid=%d name=%q", a.Type, a.Department.ID, a.Department.ParentID, a.Department.Name)
+	case SyncCreateUser, SyncUpdateUser, SyncDeleteUser:
+		return fmt.Sprintf("%s userid=%s name=%q department=%v", a.Type, a.User.UserID, a.User.Name, a.User.Department)
+	case SyncAddTagUsers, SyncDelTagUsers:
+		return fmt.Sprintf("%s tagid=%d tagname=%q userlist=%s", a.Type, a.Tag.TagID, a.Tag.TagName, strings.Join(a.UserIDList, ","))
+	default:
+		return fmt.Sprintf("%s tagid=%d tagname=%q", a.Type, a.Tag.TagID, a.Tag.TagName)
+	}
+}
+
+// SyncOptions 通讯录同步选项
+type SyncOptions struct {
+	DryRun            bool          // 只计算同步计划，不执行任何写操作
+	DeleteDepartments bool          // 删除期望状态中不存在的部门，根部门不会被删除
+	DeleteUsers       bool          // 删除期望状态中不存在的成员
+	DeleteTags        bool          // 删除期望状态中不存在的标签
+	Interval          time.Duration // 两次写操作之间的最小间隔，用于控制调用频率
+}
+
+// SyncReport 通讯录同步结果
+type SyncReport struct {
+	Actions []*SyncAction
+}
+
+// Failed 返回执行失败的操作
+func (r *SyncReport) Failed() []*SyncAction {
+	var failed []*SyncAction
+	for _, action := range r.Actions {
+		if action.Err != nil {
+			failed = append(failed, action)
+		}
+	}
+	return failed
+}
+
+// Sync 将企业微信通讯录同步为desired描述的状态
+// 执行顺序为：创建/更新部门（父部门优先）、创建/更新成员、标签变更、删除成员、删除标签、删除部门（子部门优先）
+// 单个操作失败不会中断同步，失败原因记录在对应SyncAction.Err中；只有拉取当前状态失败或ctx结束时返回error
+func (r *Client) Sync(ctx stdcontext.Context, desired *OrgTree, opts *SyncOptions) (*SyncReport, error) {
+	if opts == nil {
+		opts = &SyncOptions{}
+	}
+	for _, dept := range desired.Departments {
+		if dept.ID == 0 {
+			return nil, fmt.Errorf("department %q must have an id", dept.Name)
+		}
+	}
+	current, err := r.FetchOrgTree()
+	if err != nil {
+		return nil, err
+	}
+	report := &SyncReport{Actions: PlanSync(desired, current, opts)}
+	if opts.DryRun {
+		return report, nil
+	}
+
+	var ticker *time.Ticker
+	if opts.Interval > 0 {
+		ticker = time.NewTicker(opts.Interval)
+		defer ticker.Stop()
+	}
+	for i, action := range report.Actions {
+		if i > 0 && ticker != nil {
+			select {
+			case <-ctx.Done():
+				return report, ctx.Err()
+			case <-ticker.C:
+			}
+		} else if err = ctx.Err(); err != nil {
+			return report, err
+		}
+		action.Err = r.applySyncAction(action)
+	}
+	return report, nil
+}
+
+// FetchOrgTree 拉取企业微信通讯录的当前状态
+func (r *Client) FetchOrgTree() (*OrgTree, error) {
+	departments, err := r.DepartmentList()
+	if err != nil {
+		return nil, err
+	}
+	tree := &OrgTree{}
+	users := make(map[string]*SyncUser)
+	for _, dept := range departments {
+		tree.Departments = append(tree.Departments, &SyncDepartment{
+			ID:       dept.ID,
+			ParentID: dept.ParentID,
+			Name:     dept.Name,
+			NameEn:   dept.NameEn,
+			Order:    dept.Order,
+		})
+		var userList []*UserList
+		if userList, err = r.UserSimpleList(dept.ID); err != nil {
+			return nil, err
+		}
+		for _, user := range userList {
+			if _, ok := users[user.UserID]; ok {
+				continue
+			}
+			users[user.UserID] = &SyncUser{
+				UserID:     user.UserID,
+				Name:       user.Name,
+				Department: user.Department,
+			}
+			tree.Users = append(tree.Users, users[user.UserID])
+		}
+	}
+
+	tags, err := r.ListTag()
+	if err != nil {
+		return nil, err
+	}
+	for _, tag := range tags.TagList {
+		var members *GetTagResponse
+		if members, err = r.GetTag(tag.TagID); err != nil {
+			return nil, err
+		}
+		syncTag := &SyncTag{TagID: tag.TagID, TagName: tag.TagName}
+		for _, user := range members.UserList {
+			syncTag.UserList = append(syncTag.UserList, user.UserID)
+		}
+		tree.Tags = append(tree.Tags, syncTag)
+	}
+	return tree, nil
+}
+
+// PlanSync 计算将current同步为desired所需的操作，返回的操作已按依赖顺序排列
+func PlanSync(desired, current *OrgTree, opts *SyncOptions) []*SyncAction {
+	if opts == nil {
+		opts = &SyncOptions{}
+	}
+	var (
+		creates, updates, deletes []*SyncAction
+		userActions, userDeletes  []*SyncAction
+		tagActions, tagDeletes    []*SyncAction
+	)
+
+	// 部门
+	currentDepts := make(map[int]*SyncDepartment, len(current.Departments))
+	for _, dept := range current.Departments {
+		currentDepts[dept.ID] = dept
+	}
+	desiredDepts := make(map[int]*SyncDepartment, len(desired.Departments))
+	for _, dept := range desired.Departments {
+		desiredDepts[dept.ID] = dept
+		cur, ok := currentDepts[dept.ID]
+		switch {
+		case !ok:
+			creates = append(creates, &SyncAction{Type: SyncCreateDepartment, Department: dept})
+		case departmentChanged(dept, cur):
+			updates = append(updates, &SyncAction{Type: SyncUpdateDepartment, Department: dept})
+		}
+	}
+	if opts.DeleteDepartments {
+		for _, dept := range current.Departments {
+			if _, ok := desiredDepts[dept.ID]; !ok && dept.ID != rootDepartmentID {
+				deletes = append(deletes, &SyncAction{Type: SyncDeleteDepartment, Department: dept})
+			}
+		}
+	}
+	sortByDepth(creates, desiredDepts, currentDepts, false)
+	sortByDepth(updates, desiredDepts, currentDepts, false)
+	sortByDepth(deletes, currentDepts, nil, true)
+
+	// 成员
+	currentUsers := make(map[string]*SyncUser, len(current.Users))
+	for _, user := range current.Users {
+		currentUsers[user.UserID] = user
+	}
+	desiredUsers := make(map[string]bool, len(desired.Users))
+	for _, user := range desired.Users {
+		desiredUsers[user.UserID] = true
+		cur, ok := currentUsers[user.UserID]
+		switch {
+		case !ok:
+			userActions = append(userActions, &SyncAction{Type: SyncCreateUser, User: user})
+		case user.Name != cur.Name || !sameDepartments(user.Department, cur.Department):
+			userActions = append(userActions, &SyncAction{Type: SyncUpdateUser, User: user})
+		}
+	}
+	if opts.DeleteUsers {
+		for _, user := range current.Users {
+			if !desiredUsers[user.UserID] {
+				userDeletes = append(userDeletes, &SyncAction{Type: SyncDeleteUser, User: user})
+			}
+		}
+	}
+
+	// 标签
+	currentTagsByID := make(map[int]*SyncTag, len(current.Tags))
+	currentTagsByName := make(map[string]*SyncTag, len(current.Tags))
+	for _, tag := range current.Tags {
+		currentTagsByID[tag.TagID] = tag
+		currentTagsByName[tag.TagName] = tag
+	}
+	matchedTags := make(map[int]bool, len(desired.Tags))
+	for _, desiredTag := range desired.Tags {
+		tag := &SyncTag{TagID: desiredTag.TagID, TagName: desiredTag.TagName, UserList: desiredTag.UserList}
+		cur, ok := currentTagsByID[tag.TagID]
+		if !ok && tag.TagID == 0 {
+			cur, ok = currentTagsByName[tag.TagName]
+		}
+		var currentMembers []string
+		if ok {
+			tag.TagID = cur.TagID
+			matchedTags[cur.TagID] = true
+			currentMembers = cur.UserList
+			if cur.TagName != tag.TagName {
+				tagActions = append(tagActions, &SyncAction{Type: SyncUpdateTag, Tag: tag})
+			}
+		} else {
+			tagActions = append(tagActions, &SyncAction{Type: SyncCreateTag, Tag: tag})
+		}
+		add, del := diffStrings(tag.UserList, currentMembers)
+		if len(add) > 0 {
+			tagActions = append(tagActions, &SyncAction{Type: SyncAddTagUsers, Tag: tag, UserIDList: add})
+		}
+		if len(del) > 0 {
+			tagActions = append(tagActions, &SyncAction{Type: SyncDelTagUsers, Tag: tag, UserIDList: del})
+		}
+	}
+	if opts.DeleteTags {
+		for _, tag := range current.Tags {
+			if !matchedTags[tag.TagID] {
+				tagDeletes = append(tagDeletes, &SyncAction{Type: SyncDeleteTag, Tag: tag})
+			}
+		}
+	}
+
+	actions := make([]*SyncAction, 0, len(creates)+len(updates)+len(userActions)+len(tagActions)+len(userDeletes)+len(tagDeletes)+len(deletes))
+	actions = append(actions, creates...)
+	actions = append(actions, updates...)
+	actions = append(actions, userActions...)
+	actions = append(actions, tagActions...)
+	actions = append(actions, userDeletes...)
+	actions = append(actions, tagDeletes...)
+	actions = append(actions, deletes...)
+	return actions
+}
+
+// applySyncAction 执行一次同步操作
+func (r *Client) applySyncAction(action *SyncAction) error {
+	switch action.Type {
+	case SyncCreateDepartment:
+		_, err := r.DepartmentCreate(&DepartmentCreateRequest{
+			ID:       action.Department.ID,
+			ParentID: action.Department.ParentID,
+			Name:     action.Department.Name,
+			NameEn:   action.Department.NameEn,
+			Order:    action.Department.Order,
+		})
+		return err
+	case SyncUpdateDepartment:
+		req := &DepartmentUpdateRequest{
+			ID:       action.Department.ID,
+			ParentID: action.Department.ParentID,
+			Name:     action.Department.Name,
+			NameEn:   action.Department.NameEn,
+		}
+		// 目标次序值为0时不比较，也不修改
+		if action.Department.Order != 0 {
+			order := action.Department.Order
+			req.Order = &order
+		}
+		return r.DepartmentUpdate(req)
+	case SyncDeleteDepartment:
+		return r.DepartmentDelete(action.Department.ID)
+	case SyncCreateUser:
+		_, err := r.UserCreate(&UserCreateRequest{
+			UserID:         action.User.UserID,
+			Name:           action.User.Name,
+			Department:     action.User.Department,
+			MainDepartment: action.User.MainDepartment,
+			Mobile:         action.User.Mobile,
+			Email:          action.User.Email,
+			Position:       action.User.Position,
+			Enable:         1,
+		})
+		return err
+	case SyncUpdateUser:
+		return r.UserUpdate(&UserUpdateRequest{
+			UserID:         action.User.UserID,
+			Name:           action.User.Name,
+			Department:     action.User.Department,
+			MainDepartment: action.User.MainDepartment,
+		})
+	case SyncDeleteUser:
+		_, err := r.UserDelete(action.User.UserID)
+		return err
+	case SyncCreateTag:
+		result, err := r.CreateTag(&CreateTagRequest{TagName: action.Tag.TagName})
+		if err != nil {
+			return err
+		}
+		// 后续增加标签成员的操作共享同一个SyncTag，需要回填新标签的ID
+		action.Tag.TagID = result.TagID
+		return nil
+	case SyncUpdateTag:
+		return r.UpdateTag(&UpdateTagRequest{TagID: action.Tag.TagID, TagName: action.Tag.TagName})
+	case SyncDeleteTag:
+		return r.DeleteTag(action.Tag.TagID)
+	case SyncAddTagUsers:
+		if action.Tag.TagID == 0 {
+			return fmt.Errorf("tag %q was not created", action.Tag.TagName)
+		}
+		_, err := r.AddTagUsers(&AddTagUsersRequest{TagID: action.Tag.TagID, UserList: action.UserIDList})
+		return err
+	case SyncDelTagUsers:
+		_, err := r.DelTagUsers(&DelTagUsersRequest{TagID: action.Tag.TagID, UserList: action.UserIDList})
+		return err
+	}
+	return fmt.Errorf("unknown sync action: %s", action.Type)
+}
+
+// departmentChanged 判断部门是否需要更新
+func departmentChanged(desired, current *SyncDepartment) bool {
+	return desired.Name != current.Name ||
+		desired.ParentID != current.ParentID ||
+		(desired.NameEn != "" && desired.NameEn != current.NameEn) ||
+		(desired.Order != 0 && desired.Order != current.Order)
+}
+
+// sortByDepth 按部门层级排序，primary中找不到的父部门会在fallback中查找
+func sortByDepth(actions []*SyncAction, primary, fallback map[int]*SyncDepartment, deepestFirst bool) {
+	depth := func(dept *SyncDepartment) int {
+		d := 0
+		visited := make(map[int]bool)
+		for dept != nil && !visited[dept.ID] {
+			visited[dept.ID] = true
+			parent, ok := primary[dept.ParentID]
+			if !ok {
+				parent = fallback[dept.ParentID]
+			}
+			dept = parent
+			d++
+		}
+		return d
+	}
+	sort.SliceStable(actions, func(i, j int) bool {
+		if deepestFirst {
+			return depth(actions[i].Department) > depth(actions[j].Department)
+		}
+		return depth(actions[i].Department) < depth(actions[j].Department)
+	})
+}
+
+// sameDepartments 判断两组部门ID是否相同，忽略顺序
+func sameDepartments(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	set := make(map[int]int, len(a))
+	for _, id := range a {
+		set[id]++
+	}
+	for _, id := range b {
+		if set[id] == 0 {
+			return false
+		}
+		set[id]--
+	}
+	return true
+}
+
+// diffStrings 返回desired中有而current中没有的元素，以及current中有而desired中没有的元素
+func diffStrings(desired, current []string) (add, del []string) {
+	currentSet := make(map[string]bool, len(current))
+	for _, s := range current {
+		currentSet[s] = true
+	}
+	desiredSet := make(map[string]bool, len(desired))
+	for _, s := range desired {
+		desiredSet[s] = true
+		if !currentSet[s] {
+			add = append(add, s)
+		}
+	}
+	for _, s := range current {
+		if !desiredSet[s] {
+			del = append(del, s)
+		}
+	}
+	return add, del
+}
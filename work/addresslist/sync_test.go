@@ -0,0 +1,67 @@
+package addresslist
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlanSync(t *testing.T) {
+	current := &OrgTree{
+		Departments: []*SyncDepartment{
+			{ID: 1, Name: "root"},
+			{ID: 2, ParentID: 1, Name: "sales"},
+			{ID: 3, ParentID: 2, Name: "sales-east"},
+			{ID: 4, ParentID: 3, Name: "sales-east-1"},
+		},
+		Users: []*SyncUser{
+			{UserID: "alice", Name: "Alice", Department: []int{2}},
+			{UserID: "bob", Name: "Bob", Department: []int{4}},
+		},
+		Tags: []*SyncTag{
+			{TagID: 10, TagName: "leader", UserList: []string{"alice", "bob"}},
+			{TagID: 11, TagName: "legacy"},
+		},
+	}
+	desired := &OrgTree{
+		Departments: []*SyncDepartment{
+			{ID: 1, Name: "root"},
+			{ID: 6, ParentID: 5, Name: "rd-backend"},
+			{ID: 5, ParentID: 1, Name: "rd"},
+			{ID: 2, ParentID: 1, Name: "sales & marketing"},
+		},
+		Users: []*SyncUser{
+			{UserID: "alice", Name: "Alice", Department: []int{2}},
+			{UserID: "carol", Name: "Carol", Department: []int{6}},
+		},
+		Tags: []*SyncTag{
+			{TagName: "leader", UserList: []string{"alice", "carol"}},
+			{TagName: "oncall", UserList: []string{"carol"}},
+		},
+	}
+
+	actions := PlanSync(desired, current, &SyncOptions{DeleteDepartments: true, DeleteUsers: true, DeleteTags: true})
+	var plan []string
+	for _, action := range actions {
+		plan = append(plan, action.String())
+	}
+	assert.Equal(t, []string{
+		`create_department id=5 parentid=1 name="rd"`,
+		`create_department id=6 parentid=5 name="rd-backend"`,
+		`update_department id=2 parentid=1 name="sales & marketing"`,
+		`create_user userid=carol name="Carol" department=[6]`,
+		`add_tag_users tagid=10 tagname="leader" userlist=carol`,
+		`del_tag_users tagid=10 tagname="leader" userlist=bob`,
+		`create_tag tagid=0 tagname="oncall"`,
+		`add_tag_users tagid=0 tagname="oncall" userlist=carol`,
+		`delete_user userid=bob name="Bob" department=[4]`,
+		`delete_tag tagid=11 tagname="legacy"`,
+		`delete_department id=4 parentid=3 name="sales-east-1"`,
+		`delete_department id=3 parentid=2 name="sales-east"`,
+	}, plan)
+
+	// 默认不删除任何数据
+	for _, action := range PlanSync(desired, current, nil) {
+		assert.NotContains(t, []SyncActionType{SyncDeleteDepartment, SyncDeleteUser, SyncDeleteTag}, action.Type)
+	}
+}
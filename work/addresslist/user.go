@@ -14,8 +14,12 @@ const (
 	userCreateURL = "https://qyapi.weixin.qq.com/cgi-bin/user/create?access_token=%s"
 	// userGetURL 读取成员
 	userGetURL = "https://qyapi.weixin.qq.com/cgi-bin/user/get"
+	// userUpdateURL 更新成员
+	userUpdateURL = "https://qyapi.weixin.qq.com/cgi-bin/user/update?access_token=%s"
 	// userDeleteURL 删除成员
 	userDeleteURL = "https://qyapi.weixin.qq.com/cgi-bin/user/delete"
+	// userBatchDeleteURL 批量删除成员
+	userBatchDeleteURL = "https://qyapi.weixin.qq.com/cgi-bin/user/batchdelete?access_token=%s"
 	// userListIDURL 获取成员ID列表
 	userListIDURL = "https://qyapi.weixin.qq.com/cgi-bin/user/list_id"
 	// convertToOpenIDURL userID转openID
@@ -243,6 +247,53 @@ func (r *Client) UserGet(UserID string) (*UserGetResponse, error) {
 	return result, err
 }
 
+// UserUpdateRequest 更新成员数据请求，除userid外未填写的字段不会被修改
+type UserUpdateRequest struct {
+	UserID           string           `json:"userid"`
+	NewUserID        string           `json:"new_userid,omitempty"` // 新的userid，仅在userid由系统自动生成时允许修改一次
+	Name             string           `json:"name,omitempty"`
+	Alias            string           `json:"alias,omitempty"`
+	Mobile           string           `json:"mobile,omitempty"`
+	Department       []int            `json:"department,omitempty"`
+	Order            []int            `json:"order,omitempty"`
+	Position         string           `json:"position,omitempty"`
+	Gender           int              `json:"gender,omitempty"`
+	Email            string           `json:"email,omitempty"`
+	BizMail          string           `json:"biz_mail,omitempty"`
+	Telephone        string           `json:"telephone,omitempty"`
+	IsLeaderInDept   []int            `json:"is_leader_in_dept,omitempty"`
+	DirectLeader     []string         `json:"direct_leader,omitempty"`
+	AvatarMediaid    string           `json:"avatar_mediaid,omitempty"`
+	Enable           *int             `json:"enable,omitempty"` // 启用/禁用成员。1表示启用成员，0表示禁用成员
+	Extattr          *UserExtattr     `json:"extattr,omitempty"`
+	ExternalPosition string           `json:"external_position,omitempty"`
+	ExternalProfile  *ExternalProfile `json:"external_profile,omitempty"`
+	Address          string           `json:"address,omitempty"`
+	MainDepartment   int              `json:"main_department,omitempty"`
+}
+
+// UserExtattr 成员扩展属性
+type UserExtattr struct {
+	Attrs []ExtraAttr `json:"attrs"`
+}
+
+// UserUpdate 更新成员
+// @see https://developer.work.weixin.qq.com/document/path/90197
+func (r *Client) UserUpdate(req *UserUpdateRequest) error {
+	var (
+		accessToken string
+		err         error
+	)
+	if accessToken, err = r.GetAccessToken(); err != nil {
+		return err
+	}
+	var response []byte
+	if response, err = util.PostJSON(fmt.Sprintf(userUpdateURL, accessToken), req); err != nil {
+		return err
+	}
+	return util.DecodeWithCommonError(response, "UserUpdate")
+}
+
 type (
 	// UserDeleteResponse 删除成员数据响应
 	UserDeleteResponse struct {
@@ -275,6 +326,30 @@ func (r *Client) UserDelete(userID string) (*UserDeleteResponse, error) {
 	return result, err
 }
 
+// userBatchDeleteRequest 批量删除成员请求
+type userBatchDeleteRequest struct {
+	UserIDList []string `json:"useridlist"`
+}
+
+// UserBatchDelete 批量删除成员，每次最多删除200个成员
+// @see https://developer.work.weixin.qq.com/document/path/90199
+func (r *Client) UserBatchDelete(userIDList []string) error {
+	var (
+		accessToken string
+		err         error
+	)
+	if accessToken, err = r.GetAccessToken(); err != nil {
+		return err
+	}
+	var response []byte
+	if response, err = util.PostJSON(fmt.Sprintf(userBatchDeleteURL, accessToken), &userBatchDeleteRequest{
+		UserIDList: userIDList,
+	}); err != nil {
+		return err
+	}
+	return util.DecodeWithCommonError(response, "UserBatchDelete")
+}
+
 // UserListIDRequest 获取成员ID列表请求
 type UserListIDRequest struct {
 	Cursor string `json:"cursor"`
@@ -0,0 +1,123 @@
+package agent
+
+import (
+	"fmt"
+
+	"github.com/silenceper/wechat/v2/util"
+)
+
+const (
+	// getURL 获取指定的应用详情
+	getURL = "https://qyapi.weixin.qq.com/cgi-bin/agent/get?access_token=%s&agentid=%d"
+	// listURL 获取access_token对应的应用列表
+	listURL = "https://qyapi.weixin.qq.com/cgi-bin/agent/list?access_token=%s"
+	// setURL 设置应用
+	setURL = "https://qyapi.weixin.qq.com/cgi-bin/agent/set?access_token=%s"
+)
+
+// GetResponse 获取指定的应用详情响应
+type GetResponse struct {
+	util.CommonError
+	AgentID        int    `json:"agentid"`
+	Name           string `json:"name"`
+	SquareLogoURL  string `json:"square_logo_url"`
+	Description    string `json:"description"`
+	AllowUserInfos struct {
+		User []struct {
+			UserID string `json:"userid"`
+		} `json:"user"`
+	} `json:"allow_userinfos"` // 应用可见范围（人员）
+	AllowPartys struct {
+		PartyID []int `json:"partyid"`
+	} `json:"allow_partys"` // 应用可见范围（部门）
+	AllowTags struct {
+		TagID []int `json:"tagid"`
+	} `json:"allow_tags"` // 应用可见范围（标签）
+	Close                   int    `json:"close"`                     // 应用是否被停用。0：未被停用；1：被停用
+	RedirectDomain          string `json:"redirect_domain"`           // 应用可信域名
+	ReportLocationFlag      int    `json:"report_location_flag"`      // 应用是否打开地理位置上报 0：不上报；1：进入会话上报
+	IsReportEnter           int    `json:"isreportenter"`             // 是否上报用户进入应用事件。0：不接收；1：接收
+	HomeURL                 string `json:"home_url"`                  // 应用主页url
+	CustomizedPublishStatus int    `json:"customized_publish_status"` // 代开发自建应用返回该字段，表示代开发发布状态
+}
+
+// Get 获取指定的应用详情
+// @see https://developer.work.weixin.qq.com/document/path/90227
+func (r *Client) Get(agentID int) (*GetResponse, error) {
+	var (
+		accessToken string
+		err         error
+	)
+	if accessToken, err = r.GetAccessToken(); err != nil {
+		return nil, err
+	}
+	var response []byte
+	if response, err = util.HTTPGet(fmt.Sprintf(getURL, accessToken, agentID)); err != nil {
+		return nil, err
+	}
+	result := &GetResponse{}
+	err = util.DecodeWithError(response, result, "AgentGet")
+	return result, err
+}
+
+type (
+	// ListResponse 获取应用列表响应
+	ListResponse struct {
+		util.CommonError
+		AgentList []Agent `json:"agentlist"`
+	}
+	// Agent 应用概况
+	Agent struct {
+		AgentID       int    `json:"agentid"`
+		Name          string `json:"name"`
+		SquareLogoURL string `json:"square_logo_url"`
+	}
+)
+
+// List 获取access_token对应的应用列表
+// @see https://developer.work.weixin.qq.com/document/path/90227
+func (r *Client) List() ([]Agent, error) {
+	var (
+		accessToken string
+		err         error
+	)
+	if accessToken, err = r.GetAccessToken(); err != nil {
+		return nil, err
+	}
+	var response []byte
+	if response, err = util.HTTPGet(fmt.Sprintf(listURL, accessToken)); err != nil {
+		return nil, err
+	}
+	result := &ListResponse{}
+	err = util.DecodeWithError(response, result, "AgentList")
+	return result.AgentList, err
+}
+
+// SetRequest 设置应用请求，除agentid外未填写的字段不会被修改
+type SetRequest struct {
+	AgentID            int    `json:"agentid"`
+	ReportLocationFlag *int   `json:"report_location_flag,omitempty"` // 企业应用是否打开地理位置上报 0：不上报；1：进入会话上报
+	LogoMediaID        string `json:"logo_mediaid,omitempty"`         // 企业应用头像的mediaid，通过素材管理接口上传图片获得mediaid
+	Name               string `json:"name,omitempty"`                 // 企业应用名称，长度不超过32个utf8字符
+	Description        string `json:"description,omitempty"`          // 企业应用详情，长度为4至120个utf8字符
+	RedirectDomain     string `json:"redirect_domain,omitempty"`      // 企业应用可信域名。注意：域名需通过所有权校验，否则jssdk功能将受限
+	IsReportEnter      *int   `json:"isreportenter,omitempty"`        // 是否上报用户进入应用事件。0：不接收；1：接收
+	HomeURL            string `json:"home_url,omitempty"`             // 应用主页url。url必须以http或者https开头
+}
+
+// Set 设置应用
+// @see https://developer.work.weixin.qq.com/document/path/90228
+func (r *Client) Set(req *SetRequest) error {
+	var (
+		accessToken string
+		err         error
+	)
+	if accessToken, err = r.GetAccessToken(); err != nil {
+		return err
+	}
+	var response []byte
+	if response, err = util.PostJSON(fmt.Sprintf(setURL, accessToken), req); err != nil {
+		return err
+	}
+	return util.DecodeWithCommonError(response, "AgentSet")
+}
@@ -0,0 +1,145 @@
+package agent
+
+// Button 应用菜单按钮，一级菜单数组个数为1~3个，二级菜单数组个数为1~5个
+type Button struct {
+	Type       string    `json:"type,omitempty"`
+	Name       string    `json:"name,omitempty"`
+	Key        string    `json:"key,omitempty"`
+	URL        string    `json:"url,omitempty"`
+	AppID      string    `json:"appid,omitempty"`
+	PagePath   string    `json:"pagepath,omitempty"`
+	SubButtons []*Button `json:"sub_button,omitempty"`
+}
+
+// SetSubButton 设置二级菜单
+func (btn *Button) SetSubButton(name string, subButtons []*Button) *Button {
+	btn.Type = ""
+	btn.Name = name
+	btn.SubButtons = subButtons
+	btn.Key = ""
+	btn.URL = ""
+	btn.AppID = ""
+	btn.PagePath = ""
+	return btn
+}
+
+// setKeyButton 设置使用key回调事件的按钮
+func (btn *Button) setKeyButton(buttonType, name, key string) *Button {
+	btn.Type = buttonType
+	btn.Name = name
+	btn.Key = key
+	btn.URL = ""
+	btn.AppID = ""
+	btn.PagePath = ""
+	btn.SubButtons = nil
+	return btn
+}
+
+// SetClickButton 点击推事件
+func (btn *Button) SetClickButton(name, key string) *Button {
+	return btn.setKeyButton("click", name, key)
+}
+
+// SetViewButton 跳转URL
+func (btn *Button) SetViewButton(name, url string) *Button {
+	btn.Type = "view"
+	btn.Name = name
+	btn.URL = url
+	btn.Key = ""
+	btn.AppID = ""
+	btn.PagePath = ""
+	btn.SubButtons = nil
+	return btn
+}
+
+// SetScanCodePushButton 扫码推事件
+func (btn *Button) SetScanCodePushButton(name, key string) *Button {
+	return btn.setKeyButton("scancode_push", name, key)
+}
+
+// SetScanCodeWaitMsgButton 扫码推事件且弹出"消息接收中"提示框
+func (btn *Button) SetScanCodeWaitMsgButton(name, key string) *Button {
+	return btn.setKeyButton("scancode_waitmsg", name, key)
+}
+
+// SetPicSysPhotoButton 弹出系统拍照发图
+func (btn *Button) SetPicSysPhotoButton(name, key string) *Button {
+	return btn.setKeyButton("pic_sysphoto", name, key)
+}
+
+// SetPicPhotoOrAlbumButton 弹出拍照或者相册发图
+func (btn *Button) SetPicPhotoOrAlbumButton(name, key string) *Button {
+	return btn.setKeyButton("pic_photo_or_album", name, key)
+}
+
+// SetPicWeixinButton 弹出企业微信相册发图器
+func (btn *Button) SetPicWeixinButton(name, key string) *Button {
+	return btn.setKeyButton("pic_weixin", name, key)
+}
+
+// SetLocationSelectButton 弹出地理位置选择器
+func (btn *Button) SetLocationSelectButton(name, key string) *Button {
+	return btn.setKeyButton("location_select", name, key)
+}
+
+// SetViewMiniprogramButton 跳转到小程序，小程序必须已关联到当前应用
+func (btn *Button) SetViewMiniprogramButton(name, appID, pagePath string) *Button {
+	btn.Type = "view_miniprogram"
+	btn.Name = name
+	btn.AppID = appID
+	btn.PagePath = pagePath
+	btn.Key = ""
+	btn.URL = ""
+	btn.SubButtons = nil
+	return btn
+}
+
+// NewSubButton 二级菜单
+func NewSubButton(name string, subButtons []*Button) *Button {
+	return (&Button{}).SetSubButton(name, subButtons)
+}
+
+// NewClickButton 点击推事件
+func NewClickButton(name, key string) *Button {
+	return (&Button{}).SetClickButton(name, key)
+}
+
+// NewViewButton 跳转URL
+func NewViewButton(name, url string) *Button {
+	return (&Button{}).SetViewButton(name, url)
+}
+
+// NewScanCodePushButton 扫码推事件
+func NewScanCodePushButton(name, key string) *Button {
+	return (&Button{}).SetScanCodePushButton(name, key)
+}
+
+// NewScanCodeWaitMsgButton 扫码推事件且弹出"消息接收中"提示框
+func NewScanCodeWaitMsgButton(name, key string) *Button {
+	return (&Button{}).SetScanCodeWaitMsgButton(name, key)
+}
+
+// NewPicSysPhotoButton 弹出系统拍照发图
+func NewPicSysPhotoButton(name, key string) *Button {
+	return (&Button{}).SetPicSysPhotoButton(name, key)
+}
+
+// NewPicPhotoOrAlbumButton 弹出拍照或者相册发图
+func NewPicPhotoOrAlbumButton(name, key string) *Button {
+	return (&Button{}).SetPicPhotoOrAlbumButton(name, key)
+}
+
+// NewPicWeixinButton 弹出企业微信相册发图器
+func NewPicWeixinButton(name, key string) *Button {
+	return (&Button{}).SetPicWeixinButton(name, key)
+}
+
+// NewLocationSelectButton 弹出地理位置选择器
+func NewLocationSelectButton(name, key string) *Button {
+	return (&Button{}).SetLocationSelectButton(name, key)
+}
+
+// NewViewMiniprogramButton 跳转到小程序
+func NewViewMiniprogramButton(name, appID, pagePath string) *Button {
+	return (&Button{}).SetViewMiniprogramButton(name, appID, pagePath)
+}
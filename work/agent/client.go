@@ -0,0 +1,18 @@
+// Package agent 应用管理，实现企业微信应用管理相关接口：https://developer.work.weixin.qq.com/document/path/90226
+package agent
+
+import (
+	"github.com/silenceper/wechat/v2/work/context"
+)
+
+// Client 应用管理接口实例
+type Client struct {
+	*context.Context
+}
+
+// NewClient 初始化实例
+func NewClient(ctx *context.Context) *Client {
+	return &Client{
+		ctx,
+	}
+}
@@ -0,0 +1,83 @@
+package agent
+
+import (
+	"fmt"
+
+	"github.com/silenceper/wechat/v2/util"
+)
+
+const (
+	// menuCreateURL 创建菜单
+	menuCreateURL = "https://qyapi.weixin.qq.com/cgi-bin/menu/create?access_token=%s&agentid=%d"
+	// menuGetURL 获取菜单
+	menuGetURL = "https://qyapi.weixin.qq.com/cgi-bin/menu/get?access_token=%s&agentid=%d"
+	// menuDeleteURL 删除菜单
+	menuDeleteURL = "https://qyapi.weixin.qq.com/cgi-bin/menu/delete?access_token=%s&agentid=%d"
+)
+
+type (
+	// menuRequest 创建菜单请求
+	menuRequest struct {
+		Button []*Button `json:"button"`
+	}
+	// MenuGetResponse 获取菜单响应
+	MenuGetResponse struct {
+		util.CommonError
+		Button []*Button `json:"button"`
+	}
+)
+
+// CreateMenu 创建应用菜单，会覆盖应用已有的菜单
+// @see https://developer.work.weixin.qq.com/document/path/90231
+func (r *Client) CreateMenu(agentID int, buttons []*Button) error {
+	var (
+		accessToken string
+		err         error
+	)
+	if accessToken, err = r.GetAccessToken(); err != nil {
+		return err
+	}
+	var response []byte
+	if response, err = util.PostJSON(fmt.Sprintf(menuCreateURL, accessToken, agentID), &menuRequest{
+		Button: buttons,
+	}); err != nil {
+		return err
+	}
+	return util.DecodeWithCommonError(response, "MenuCreate")
+}
+
+// GetMenu 获取应用菜单
+// @see https://developer.work.weixin.qq.com/document/path/90232
+func (r *Client) GetMenu(agentID int) ([]*Button, error) {
+	var (
+		accessToken string
+		err         error
+	)
+	if accessToken, err = r.GetAccessToken(); err != nil {
+		return nil, err
+	}
+	var response []byte
+	if response, err = util.HTTPGet(fmt.Sprintf(menuGetURL, accessToken, agentID)); err != nil {
+		return nil, err
+	}
+	result := &MenuGetResponse{}
+	err = util.DecodeWithError(response, result, "MenuGet")
+	return result.Button, err
+}
+
+// DeleteMenu 删除应用菜单
+// @see https://developer.work.weixin.qq.com/document/path/90233
+func (r *Client) DeleteMenu(agentID int) error {
+	var (
+		accessToken string
+		err         error
+	)
+	if accessToken, err = r.GetAccessToken(); err != nil {
+		return err
+	}
+	var response []byte
+	if response, err = util.HTTPGet(fmt.Sprintf(menuDeleteURL, accessToken, agentID)); err != nil {
+		return err
+	}
+	return util.DecodeWithCommonError(response, "MenuDelete")
+}
@@ -60,6 +60,108 @@ type (
 		// 语音文件id，可以调用上传临时素材接口获取
 		MediaID string `json:"media_id"`
 	}
+
+	// SendVideoRequest 发送视频消息的请求
+	SendVideoRequest struct {
+		*SendRequestCommon
+		Video VideoField `json:"video"`
+	}
+	// VideoField 视频消息参数
+	VideoField struct {
+		// 视频媒体文件id，可以调用上传临时素材接口获取
+		MediaID string `json:"media_id"`
+		// 视频消息的标题，不超过128个字节，超过会自动截断
+		Title string `json:"title,omitempty"`
+		// 视频消息的描述，不超过512个字节，超过会自动截断
+		Description string `json:"description,omitempty"`
+	}
+
+	// SendFileRequest 发送文件消息的请求
+	SendFileRequest struct {
+		*SendRequestCommon
+		File FileField `json:"file"`
+	}
+	// FileField 文件消息参数
+	FileField struct {
+		// 文件id，可以调用上传临时素材接口获取
+		MediaID string `json:"media_id"`
+	}
+
+	// SendTextCardRequest 发送文本卡片消息的请求
+	SendTextCardRequest struct {
+		*SendRequestCommon
+		TextCard TextCardField `json:"textcard"`
+	}
+	// TextCardField 文本卡片消息参数
+	TextCardField struct {
+		// 标题，不超过128个字节，超过会自动截断
+		Title string `json:"title"`
+		// 描述，不超过512个字节，超过会自动截断
+		Description string `json:"description"`
+		// 点击后跳转的链接。最长2048字节，请确保包含了协议头(http/https)
+		URL string `json:"url"`
+		// 按钮文字。 默认为“详情”， 不超过4个文字，超过自动截断
+		BtnTxt string `json:"btntxt,omitempty"`
+	}
+
+	// SendNewsRequest 发送图文消息的请求
+	SendNewsRequest struct {
+		*SendRequestCommon
+		News NewsField `json:"news"`
+	}
+	// NewsField 图文消息参数
+	NewsField struct {
+		// 图文消息，一个图文消息支持1到8条图文
+		Articles []NewsArticle `json:"articles"`
+	}
+	// NewsArticle 图文消息中的一条图文
+	NewsArticle struct {
+		// 标题，不超过128个字节，超过会自动截断
+		Title string `json:"title"`
+		// 描述，不超过512个字节，超过会自动截断
+		Description string `json:"description,omitempty"`
+		// 点击后跳转的链接。最长2048字节，请确保包含了协议头(http/https)
+		URL string `json:"url"`
+		// 图文消息的图片链接，支持JPG、PNG格式，较好的效果为大图 1068*455，小图150*150
+		PicURL string `json:"picurl,omitempty"`
+	}
+
+	// SendMpNewsRequest 发送图文消息（mpnews）的请求
+	SendMpNewsRequest struct {
+		*SendRequestCommon
+		MpNews MpNewsField `json:"mpnews"`
+	}
+	// MpNewsField 图文消息（mpnews）参数
+	MpNewsField struct {
+		// 图文消息，一个图文消息支持1到8条图文
+		Articles []MpNewsArticle `json:"articles"`
+	}
+	// MpNewsArticle 图文消息（mpnews）中的一条图文
+	MpNewsArticle struct {
+		// 标题，不超过128个字节，超过会自动截断
+		Title string `json:"title"`
+		// 图文消息缩略图的media_id, 可以通过素材管理接口获得
+		ThumbMediaID string `json:"thumb_media_id"`
+		// 图文消息的作者，不超过64个字节
+		Author string `json:"author,omitempty"`
+		// 图文消息点击“阅读原文”之后的页面链接
+		ContentSourceURL string `json:"content_source_url,omitempty"`
+		// 图文消息的内容，支持html标签，不超过666 K个字节
+		Content string `json:"content"`
+		// 图文消息的描述，不超过512个字节，超过会自动截断
+		Digest string `json:"digest,omitempty"`
+	}
+
+	// SendMarkdownRequest 发送markdown消息的请求
+	SendMarkdownRequest struct {
+		*SendRequestCommon
+		Markdown MarkdownField `json:"markdown"`
+	}
+	// MarkdownField markdown消息参数
+	MarkdownField struct {
+		// markdown内容，最长不超过2048个字节，必须是utf8编码
+		Content string `json:"content"`
+	}
 )
 
 // Send 发送应用消息
@@ -108,6 +210,44 @@ func (r *Client) SendVoice(request SendVoiceRequest) (*SendResponse, error) {
 	return r.Send("MessageSendVoice", request)
 }
 
-// 以上实现了部分常用消息推送：SendText 发送文本消息、SendImage 发送图片消息、SendVoice 发送语音消息，
-// 如需扩展其他消息类型，建议按照以上格式，扩展对应消息类型的参数即可
-// 也可以直接使用Send方法，按照企业微信消息推送的接口文档传对应消息类型的参数来使用
+// SendVideo 发送视频消息
+func (r *Client) SendVideo(request SendVideoRequest) (*SendResponse, error) {
+	// 发送视频消息MsgType参数固定为：video
+	request.MsgType = "video"
+	return r.Send("MessageSendVideo", request)
+}
+
+// SendFile 发送文件消息
+func (r *Client) SendFile(request SendFileRequest) (*SendResponse, error) {
+	// 发送文件消息MsgType参数固定为：file
+	request.MsgType = "file"
+	return r.Send("MessageSendFile", request)
+}
+
+// SendTextCard 发送文本卡片消息
+func (r *Client) SendTextCard(request SendTextCardRequest) (*SendResponse, error) {
+	// 发送文本卡片消息MsgType参数固定为：textcard
+	request.MsgType = "textcard"
+	return r.Send("MessageSendTextCard", request)
+}
+
+// SendNews 发送图文消息
+func (r *Client) SendNews(request SendNewsRequest) (*SendResponse, error) {
+	// 发送图文消息MsgType参数固定为：news
+	request.MsgType = "news"
+	return r.Send("MessageSendNews", request)
+}
+
+// SendMpNews 发送图文消息（mpnews）
+func (r *Client) SendMpNews(request SendMpNewsRequest) (*SendResponse, error) {
+	// 发送图文消息（mpnews）MsgType参数固定为：mpnews
+	request.MsgType = "mpnews"
+	return r.Send("MessageSendMpNews", request)
+}
+
+// SendMarkdown 发送markdown消息
+func (r *Client) SendMarkdown(request SendMarkdownRequest) (*SendResponse, error) {
+	// 发送markdown消息MsgType参数固定为：markdown
+	request.MsgType = "markdown"
+	return r.Send("MessageSendMarkdown", request)
+}
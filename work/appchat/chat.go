@@ -0,0 +1,140 @@
+package appchat
+
+import (
+	"fmt"
+
+	"github.com/silenceper/wechat/v2/util"
+)
+
+const (
+	// createURL 创建群聊会话
+	createURL = "https://qyapi.weixin.qq.com/cgi-bin/appchat/create?access_token=%s"
+	// updateURL 修改群聊会话
+	updateURL = "https://qyapi.weixin.qq.com/cgi-bin/appchat/update?access_token=%s"
+	// getURL 获取群聊会话
+	getURL = "https://qyapi.weixin.qq.com/cgi-bin/appchat/get?access_token=%s&chatid=%s"
+)
+
+type (
+	// CreateRequest 创建群聊会话请求
+	CreateRequest struct {
+		// 群聊名，最多50个utf8字符，超过将截断
+		Name string `json:"name,omitempty"`
+		// 指定群主的id。如果不指定，系统会随机从userlist中选一人作为群主
+		Owner string `json:"owner,omitempty"`
+		// 群成员id列表。至少2人，至多2000人
+		UserList []string `json:"userlist"`
+		// 群聊的唯一标志，不能与已有的群重复；字符串类型，最长32个字符。只允许字符0-9及字母a-zA-Z。如果不填，系统会随机生成群id
+		ChatID string `json:"chatid,omitempty"`
+	}
+	// CreateResponse 创建群聊会话响应
+	CreateResponse struct {
+		util.CommonError
+		ChatID string `json:"chatid"`
+	}
+)
+
+// Create 创建群聊会话，返回群聊id
+// @see https://developer.work.weixin.qq.com/document/path/90245
+func (r *Client) Create(req *CreateRequest) (string, error) {
+	var (
+		accessToken string
+		err         error
+	)
+	if accessToken, err = r.GetAccessToken(); err != nil {
+		return "", err
+	}
+	var response []byte
+	if response, err = util.PostJSON(fmt.Sprintf(createURL, accessToken), req); err != nil {
+		return "", err
+	}
+	result := &CreateResponse{}
+	err = util.DecodeWithError(response, result, "AppChatCreate")
+	return result.ChatID, err
+}
+
+// UpdateRequest 修改群聊会话请求，未填写的字段不会被修改
+type UpdateRequest struct {
+	// 群聊id
+	ChatID string `json:"chatid"`
+	// 新的群聊名。若不需更新，请忽略此参数。最多50个utf8字符，超过将截断
+	Name string `json:"name,omitempty"`
+	// 新群主的id。若不需更新，请忽略此参数。课堂群的群主暂不支持通过接口变更
+	Owner string `json:"owner,omitempty"`
+	// 添加成员的id列表
+	AddUserList []string `json:"add_user_list,omitempty"`
+	// 踢出成员的id列表
+	DelUserList []string `json:"del_user_list,omitempty"`
+}
+
+// Update 修改群聊会话
+// @see https://developer.work.weixin.qq.com/document/path/90246
+func (r *Client) Update(req *UpdateRequest) error {
+	var (
+		accessToken string
+		err         error
+	)
+	if accessToken, err = r.GetAccessToken(); err != nil {
+		return err
+	}
+	var response []byte
+	if response, err = util.PostJSON(fmt.Sprintf(updateURL, accessToken), req); err != nil {
+		return err
+	}
+	return util.DecodeWithCommonError(response, "AppChatUpdate")
+}
+
+// AddMembers 向群聊会话添加成员
+func (r *Client) AddMembers(chatID string, userIDs ...string) error {
+	return r.Update(&UpdateRequest{ChatID: chatID, AddUserList: userIDs})
+}
+
+// RemoveMembers 从群聊会话踢出成员
+func (r *Client) RemoveMembers(chatID string, userIDs ...string) error {
+	return r.Update(&UpdateRequest{ChatID: chatID, DelUserList: userIDs})
+}
+
+// Rename 修改群聊名
+func (r *Client) Rename(chatID, name string) error {
+	return r.Update(&UpdateRequest{ChatID: chatID, Name: name})
+}
+
+// TransferOwner 变更群主
+func (r *Client) TransferOwner(chatID, owner string) error {
+	return r.Update(&UpdateRequest{ChatID: chatID, Owner: owner})
+}
+
+type (
+	// GetResponse 获取群聊会话响应
+	GetResponse struct {
+		util.CommonError
+		ChatInfo ChatInfo `json:"chat_info"`
+	}
+	// ChatInfo 群聊信息
+	ChatInfo struct {
+		ChatID   string   `json:"chatid"`
+		Name     string   `json:"name"`
+		Owner    string   `json:"owner"`
+		UserList []string `json:"userlist"`
+		ChatType int      `json:"chat_type"` // 群聊类型。0：普通群；1：家校群
+	}
+)
+
+// Get 获取群聊会话
+// @see https://developer.work.weixin.qq.com/document/path/90247
+func (r *Client) Get(chatID string) (*ChatInfo, error) {
+	var (
+		accessToken string
+		err         error
+	)
+	if accessToken, err = r.GetAccessToken(); err != nil {
+		return nil, err
+	}
+	var response []byte
+	if response, err = util.HTTPGet(fmt.Sprintf(getURL, accessToken, chatID)); err != nil {
+		return nil, err
+	}
+	result := &GetResponse{}
+	err = util.DecodeWithError(response, result, "AppChatGet")
+	return &result.ChatInfo, err
+}
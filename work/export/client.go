@@ -0,0 +1,18 @@
+// Package export 异步导出接口，实现企业微信异步导出通讯录相关接口：https://developer.work.weixin.qq.com/document/path/94849
+package export
+
+import (
+	"github.com/silenceper/wechat/v2/work/context"
+)
+
+// Client 异步导出接口实例
+type Client struct {
+	*context.Context
+}
+
+// NewClient 初始化实例
+func NewClient(ctx *context.Context) *Client {
+	return &Client{
+		ctx,
+	}
+}
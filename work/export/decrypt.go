@@ -0,0 +1,107 @@
+package export
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/base64"
+	"fmt"
+	"io"
+)
+
+// paddingBlockSize 导出文件使用PKCS#7填充，填充的块大小为32字节
+const paddingBlockSize = 32
+
+// readChunkSize 每次从下载流读取的字节数
+const readChunkSize = 32 * 1024
+
+// decodeAESKey 将43位的EncodingAESKey解码为32字节的AES密钥
+func decodeAESKey(encodingAESKey string) ([]byte, error) {
+	if len(encodingAESKey) != 43 {
+		return nil, fmt.Errorf("the length of encoding_aeskey must be 43, got %d", len(encodingAESKey))
+	}
+	return base64.StdEncoding.DecodeString(encodingAESKey + "=")
+}
+
+// decryptReader 以流的方式解密导出文件，文件使用AES-256-CBC加密，IV为密钥的前16字节
+type decryptReader struct {
+	src   io.Reader
+	mode  cipher.BlockMode
+	raw   []byte // 尚未解密的密文，不足一个AES块
+	tail  []byte // 已解密但可能包含填充的明文，直到读取结束才能确定
+	plain []byte // 可以输出的明文
+	eof   bool
+}
+
+// newDecryptReader 创建解密src的Reader
+func newDecryptReader(src io.Reader, encodingAESKey string) (io.Reader, error) {
+	key, err := decodeAESKey(encodingAESKey)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return &decryptReader{
+		src:  src,
+		mode: cipher.NewCBCDecrypter(block, key[:aes.BlockSize]),
+	}, nil
+}
+
+// Read 实现 io.Reader
+func (r *decryptReader) Read(p []byte) (int, error) {
+	for len(r.plain) == 0 {
+		if r.eof {
+			return 0, io.EOF
+		}
+		if err := r.fill(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, r.plain)
+	r.plain = r.plain[n:]
+	return n, nil
+}
+
+// fill 读取并解密下一段密文
+func (r *decryptReader) fill() error {
+	chunk := make([]byte, readChunkSize)
+	n, err := r.src.Read(chunk)
+	r.raw = append(r.raw, chunk[:n]...)
+	if err != nil && err != io.EOF {
+		return err
+	}
+
+	size := len(r.raw) / aes.BlockSize * aes.BlockSize
+	if size > 0 {
+		decrypted := make([]byte, size)
+		r.mode.CryptBlocks(decrypted, r.raw[:size])
+		r.raw = append([]byte(nil), r.raw[size:]...)
+		r.tail = append(r.tail, decrypted...)
+	}
+
+	if err == io.EOF {
+		r.eof = true
+		if len(r.raw) != 0 {
+			return fmt.Errorf("ciphertext is not a multiple of the block size")
+		}
+		if len(r.tail) == 0 {
+			return nil
+		}
+		pad := int(r.tail[len(r.tail)-1])
+		if pad < 1 || pad > paddingBlockSize || pad > len(r.tail) {
+			return fmt.Errorf("invalid padding size %d", pad)
+		}
+		r.plain = append(r.plain, r.tail[:len(r.tail)-pad]...)
+		r.tail = nil
+		return nil
+	}
+
+	// 保留最后一个填充块，其余明文可以输出
+	if len(r.tail) > paddingBlockSize {
+		cut := len(r.tail) - paddingBlockSize
+		r.plain = append(r.plain, r.tail[:cut]...)
+		r.tail = append([]byte(nil), r.tail[cut:]...)
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package export
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/json"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func encryptForTest(t *testing.T, encodingAESKey string, plain []byte) []byte {
+	key, err := decodeAESKey(encodingAESKey)
+	assert.Nil(t, err)
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+	pad := paddingBlockSize - len(plain)%paddingBlockSize
+	plain = append(plain, bytes.Repeat([]byte{byte(pad)}, pad)...)
+	ciphertext := make([]byte, len(plain))
+	cipher.NewCBCEncrypter(block, key[:aes.BlockSize]).CryptBlocks(ciphertext, plain)
+	return ciphertext
+}
+
+func TestDecryptReader(t *testing.T) {
+	encodingAESKey := "abcdefghijklmnopqrstuvwxyz0123456789ABCDEFG"
+	plain := []byte(`{"errcode":0,"department":[{"id":1,"name":"root","parentid":0,"order":100},{"id":2,"name":"研发","parentid":1,"order":50}],"total":2}`)
+	ciphertext := encryptForTest(t, encodingAESKey, plain)
+
+	reader, err := newDecryptReader(iotest.OneByteReader(bytes.NewReader(ciphertext)), encodingAESKey)
+	assert.Nil(t, err)
+	var departments []*Department
+	err = decodeArrayField(json.NewDecoder(reader), "department", func(dec *json.Decoder) error {
+		record := &Department{}
+		if err := dec.Decode(record); err != nil {
+			return err
+		}
+		departments = append(departments, record)
+		return nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []*Department{
+		{ID: 1, Name: "root", Order: 100},
+		{ID: 2, Name: "研发", ParentID: 1, Order: 50},
+	}, departments)
+
+	_, err = newDecryptReader(bytes.NewReader(ciphertext), "short")
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,127 @@
+package export
+
+import (
+	"fmt"
+
+	"github.com/silenceper/wechat/v2/util"
+)
+
+const (
+	// simpleUserURL 导出成员
+	simpleUserURL = "https://qyapi.weixin.qq.com/cgi-bin/export/simple_user?access_token=%s"
+	// userURL 导出成员详情
+	userURL = "https://qyapi.weixin.qq.com/cgi-bin/export/user?access_token=%s"
+	// departmentURL 导出部门
+	departmentURL = "https://qyapi.weixin.qq.com/cgi-bin/export/department?access_token=%s"
+	// tagUserURL 导出标签成员
+	tagUserURL = "https://qyapi.weixin.qq.com/cgi-bin/export/taguser?access_token=%s"
+	// getResultURL 获取导出结果
+	getResultURL = "https://qyapi.weixin.qq.com/cgi-bin/export/get_result?access_token=%s&jobid=%s"
+)
+
+// 导出任务状态
+const (
+	// StatusPending 未处理
+	StatusPending = 0
+	// StatusProcessing 处理中
+	StatusProcessing = 1
+	// StatusFinished 完成
+	StatusFinished = 2
+	// StatusFailed 异常失败
+	StatusFailed = 3
+)
+
+type (
+	// Request 导出请求
+	Request struct {
+		EncodingAESKey string `json:"encoding_aeskey"`      // base64encode的加密密钥，长度固定为43，用于解密导出的文件
+		BlockSize      int    `json:"block_size,omitempty"` // 每块数据的数量，支持范围[10^4,10^6]，默认值为10^6
+	}
+	// TagUserRequest 导出标签成员请求
+	TagUserRequest struct {
+		TagID          int    `json:"tagid"`
+		EncodingAESKey string `json:"encoding_aeskey"`
+		BlockSize      int    `json:"block_size,omitempty"`
+	}
+	// JobResponse 导出任务提交响应
+	JobResponse struct {
+		util.CommonError
+		JobID string `json:"jobid"`
+	}
+)
+
+// SimpleUser 导出成员，导出的数据包含成员userid、name与所在部门
+// @see https://developer.work.weixin.qq.com/document/path/94849
+func (r *Client) SimpleUser(req *Request) (string, error) {
+	return r.submit(simpleUserURL, req, "ExportSimpleUser")
+}
+
+// User 导出成员详情
+// @see https://developer.work.weixin.qq.com/document/path/94851
+func (r *Client) User(req *Request) (string, error) {
+	return r.submit(userURL, req, "ExportUser")
+}
+
+// Department 导出部门
+// @see https://developer.work.weixin.qq.com/document/path/94852
+func (r *Client) Department(req *Request) (string, error) {
+	return r.submit(departmentURL, req, "ExportDepartment")
+}
+
+// TagUser 导出标签成员
+// @see https://developer.work.weixin.qq.com/document/path/94853
+func (r *Client) TagUser(req *TagUserRequest) (string, error) {
+	return r.submit(tagUserURL, req, "ExportTagUser")
+}
+
+// submit 提交导出任务并返回jobid
+func (r *Client) submit(urlFormat string, req interface{}, apiName string) (string, error) {
+	var (
+		accessToken string
+		err         error
+	)
+	if accessToken, err = r.GetAccessToken(); err != nil {
+		return "", err
+	}
+	var response []byte
+	if response, err = util.PostJSON(fmt.Sprintf(urlFormat, accessToken), req); err != nil {
+		return "", err
+	}
+	result := &JobResponse{}
+	err = util.DecodeWithError(response, result, apiName)
+	return result.JobID, err
+}
+
+type (
+	// GetResultResponse 获取导出结果响应
+	GetResultResponse struct {
+		util.CommonError
+		Status   int        `json:"status"`    // 任务状态:0-未处理，1-处理中，2-完成，3-异常失败
+		DataList []DataPart `json:"data_list"` // 数据文件列表，仅任务完成时返回
+	}
+	// DataPart 导出的一个加密数据文件
+	DataPart struct {
+		URL  string `json:"url"` // 数据下载链接,支持指定Range头部分段下载。有效期2个小时
+		Size int64  `json:"size"`
+		MD5  string `json:"md5"`
+	}
+)
+
+// GetResult 获取导出结果
+// @see https://developer.work.weixin.qq.com/document/path/94854
+func (r *Client) GetResult(jobID string) (*GetResultResponse, error) {
+	var (
+		accessToken string
+		err         error
+	)
+	if accessToken, err = r.GetAccessToken(); err != nil {
+		return nil, err
+	}
+	var response []byte
+	if response, err = util.HTTPGet(fmt.Sprintf(getResultURL, accessToken, jobID)); err != nil {
+		return nil, err
+	}
+	result := &GetResultResponse{}
+	err = util.DecodeWithError(response, result, "ExportGetResult")
+	return result, err
+}
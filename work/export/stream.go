@@ -0,0 +1,285 @@
+package export
+
+import (
+	stdcontext "context"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/silenceper/wechat/v2/util"
+)
+
+// defaultPollInterval 默认的导出结果轮询间隔
+const defaultPollInterval = 3 * time.Second
+
+type (
+	// SimpleUser 导出的成员
+	SimpleUser struct {
+		UserID     string `json:"userid"`
+		Name       string `json:"name"`
+		Department []int  `json:"department"`
+		OpenUserID string `json:"open_userid"`
+	}
+	// User 导出的成员详情
+	User struct {
+		UserID         string   `json:"userid"`
+		Name           string   `json:"name"`
+		Department     []int    `json:"department"`
+		Order          []int    `json:"order"`
+		Position       string   `json:"position"`
+		Mobile         string   `json:"mobile"`
+		Gender         string   `json:"gender"`
+		Email          string   `json:"email"`
+		BizMail        string   `json:"biz_mail"`
+		IsLeaderInDept []int    `json:"is_leader_in_dept"`
+		DirectLeader   []string `json:"direct_leader"`
+		Avatar         string   `json:"avatar"`
+		ThumbAvatar    string   `json:"thumb_avatar"`
+		Telephone      string   `json:"telephone"`
+		Alias          string   `json:"alias"`
+		Address        string   `json:"address"`
+		OpenUserID     string   `json:"open_userid"`
+		MainDepartment int      `json:"main_department"`
+		Status         int      `json:"status"`
+		QrCode         string   `json:"qr_code"`
+		Extattr        struct {
+			Attrs []struct {
+				Type int    `json:"type"`
+				Name string `json:"name"`
+				Text struct {
+					Value string `json:"value"`
+				} `json:"text,omitempty"`
+				Web struct {
+					URL   string `json:"url"`
+					Title string `json:"title"`
+				} `json:"web,omitempty"`
+			} `json:"attrs"`
+		} `json:"extattr"`
+		ExternalPosition string `json:"external_position"`
+	}
+	// Department 导出的部门
+	Department struct {
+		ID       int    `json:"id"`
+		Name     string `json:"name"`
+		ParentID int    `json:"parentid"`
+		Order    int    `json:"order"`
+	}
+	// TagUser 导出的标签成员
+	TagUser struct {
+		UserID     string `json:"userid"`
+		Department []int  `json:"department"`
+	}
+)
+
+// ErrMissingAESKey 导出选项为空或未设置EncodingAESKey
+var ErrMissingAESKey = errors.New("export options must set EncodingAESKey")
+
+// Options 导出选项，EncodingAESKey必须设置
+type Options struct {
+	EncodingAESKey string        // base64encode的加密密钥，长度固定为43
+	BlockSize      int           // 每块数据的数量，支持范围[10^4,10^6]，默认值为10^6
+	PollInterval   time.Duration // 轮询导出结果的间隔，默认3秒
+}
+
+// Wait 轮询导出结果直至任务完成，任务异常失败时返回错误
+func (r *Client) Wait(ctx stdcontext.Context, jobID string, interval time.Duration) ([]DataPart, error) {
+	if interval <= 0 {
+		interval = defaultPollInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		result, err := r.GetResult(jobID)
+		if err != nil {
+			return nil, err
+		}
+		switch result.Status {
+		case StatusFinished:
+			return result.DataList, nil
+		case StatusFailed:
+			return nil, fmt.Errorf("export job %s failed", jobID)
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// StreamSimpleUsers 导出成员，并逐条回调解密后的记录，fn返回错误时停止导出
+func (r *Client) StreamSimpleUsers(ctx stdcontext.Context, opts *Options, fn func(*SimpleUser) error) error {
+	if err := checkOptions(opts); err != nil {
+		return err
+	}
+	jobID, err := r.SimpleUser(&Request{EncodingAESKey: opts.EncodingAESKey, BlockSize: opts.BlockSize})
+	if err != nil {
+		return err
+	}
+	return r.stream(ctx, jobID, opts, "userlist", func(dec *json.Decoder) error {
+		record := &SimpleUser{}
+		if err := dec.Decode(record); err != nil {
+			return err
+		}
+		return fn(record)
+	})
+}
+
+// StreamUsers 导出成员详情，并逐条回调解密后的记录，fn返回错误时停止导出
+func (r *Client) StreamUsers(ctx stdcontext.Context, opts *Options, fn func(*User) error) error {
+	if err := checkOptions(opts); err != nil {
+		return err
+	}
+	jobID, err := r.User(&Request{EncodingAESKey: opts.EncodingAESKey, BlockSize: opts.BlockSize})
+	if err != nil {
+		return err
+	}
+	return r.stream(ctx, jobID, opts, "userlist", func(dec *json.Decoder) error {
+		record := &User{}
+		if err := dec.Decode(record); err != nil {
+			return err
+		}
+		return fn(record)
+	})
+}
+
+// StreamDepartments 导出部门，并逐条回调解密后的记录，fn返回错误时停止导出
+func (r *Client) StreamDepartments(ctx stdcontext.Context, opts *Options, fn func(*Department) error) error {
+	if err := checkOptions(opts); err != nil {
+		return err
+	}
+	jobID, err := r.Department(&Request{EncodingAESKey: opts.EncodingAESKey, BlockSize: opts.BlockSize})
+	if err != nil {
+		return err
+	}
+	return r.stream(ctx, jobID, opts, "department", func(dec *json.Decoder) error {
+		record := &Department{}
+		if err := dec.Decode(record); err != nil {
+			return err
+		}
+		return fn(record)
+	})
+}
+
+// StreamTagUsers 导出标签成员，并逐条回调解密后的记录，fn返回错误时停止导出
+func (r *Client) StreamTagUsers(ctx stdcontext.Context, tagID int, opts *Options, fn func(*TagUser) error) error {
+	if err := checkOptions(opts); err != nil {
+		return err
+	}
+	jobID, err := r.TagUser(&TagUserRequest{TagID: tagID, EncodingAESKey: opts.EncodingAESKey, BlockSize: opts.BlockSize})
+	if err != nil {
+		return err
+	}
+	return r.stream(ctx, jobID, opts, "userlist", func(dec *json.Decoder) error {
+		record := &TagUser{}
+		if err := dec.Decode(record); err != nil {
+			return err
+		}
+		return fn(record)
+	})
+}
+
+// checkOptions 创建导出任务前检查导出选项，导出的数据需要使用EncodingAESKey解密
+func checkOptions(opts *Options) error {
+	if opts == nil || opts.EncodingAESKey == "" {
+		return ErrMissingAESKey
+	}
+	_, err := decodeAESKey(opts.EncodingAESKey)
+	return err
+}
+
+// stream 等待导出任务完成，依次下载并解密每个数据文件，对field数组中的每个元素调用decode
+func (r *Client) stream(ctx stdcontext.Context, jobID string, opts *Options, field string, decode func(*json.Decoder) error) error {
+	parts, err := r.Wait(ctx, jobID, opts.PollInterval)
+	if err != nil {
+		return err
+	}
+	for _, part := range parts {
+		if err = streamPart(ctx, part, opts.EncodingAESKey, field, decode); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// streamPart 下载并解密一个数据文件，读取结束后校验文件的md5
+func streamPart(ctx stdcontext.Context, part DataPart, encodingAESKey, field string, decode func(*json.Decoder) error) error {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, part.URL, nil)
+	if err != nil {
+		return err
+	}
+	response, err := util.DefaultHTTPClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("http get error : uri=%v , statusCode=%v", part.URL, response.StatusCode)
+	}
+
+	hash := md5.New()
+	reader, err := newDecryptReader(io.TeeReader(response.Body, hash), encodingAESKey)
+	if err != nil {
+		return err
+	}
+	if err = decodeArrayField(json.NewDecoder(reader), field, decode); err != nil {
+		return err
+	}
+	// 解析完成后读完剩余的数据，保证md5覆盖整个文件
+	if _, err = io.Copy(io.Discard, reader); err != nil {
+		return err
+	}
+	if part.MD5 != "" && hex.EncodeToString(hash.Sum(nil)) != part.MD5 {
+		return fmt.Errorf("md5 mismatch for export data %s", part.URL)
+	}
+	return nil
+}
+
+// decodeArrayField 流式解析JSON对象，对field数组中的每个元素调用decode，其他字段被忽略
+func decodeArrayField(dec *json.Decoder, field string, decode func(*json.Decoder) error) error {
+	if err := expectDelim(dec, '{'); err != nil {
+		return err
+	}
+	for dec.More() {
+		token, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		if key, _ := token.(string); key != field {
+			var skip json.RawMessage
+			if err = dec.Decode(&skip); err != nil {
+				return err
+			}
+			continue
+		}
+		if err = expectDelim(dec, '['); err != nil {
+			return err
+		}
+		for dec.More() {
+			if err = decode(dec); err != nil {
+				return err
+			}
+		}
+		if err = expectDelim(dec, ']'); err != nil {
+			return err
+		}
+	}
+	return expectDelim(dec, '}')
+}
+
+// expectDelim 读取下一个token并确认是指定的分隔符
+func expectDelim(dec *json.Decoder, delim json.Delim) error {
+	token, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if d, ok := token.(json.Delim); !ok || d != delim {
+		return fmt.Errorf("unexpected json token %v, want %v", token, delim)
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+package export
+
+import (
+	stdcontext "context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStreamOptions(t *testing.T) {
+	// 选项无效时在创建导出任务前返回错误，不会调用接口
+	client := &Client{}
+	ctx := stdcontext.Background()
+	streams := map[string]func(*Options) error{
+		"simple users": func(opts *Options) error {
+			return client.StreamSimpleUsers(ctx, opts, func(*SimpleUser) error { return nil })
+		},
+		"users": func(opts *Options) error {
+			return client.StreamUsers(ctx, opts, func(*User) error { return nil })
+		},
+		"departments": func(opts *Options) error {
+			return client.StreamDepartments(ctx, opts, func(*Department) error { return nil })
+		},
+		"tag users": func(opts *Options) error {
+			return client.StreamTagUsers(ctx, 1, opts, func(*TagUser) error { return nil })
+		},
+	}
+	for name, stream := range streams {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, ErrMissingAESKey, stream(nil))
+			assert.Equal(t, ErrMissingAESKey, stream(&Options{BlockSize: 10000}))
+			assert.ErrorContains(t, stream(&Options{EncodingAESKey: "short"}), "must be 43")
+		})
+	}
+}
@@ -13,19 +13,128 @@ type callbackOriginMessage struct {
 	Encrypt    string // 消息结构体加密后的字符串
 }
 
-// EventCallbackMessage 微信客户联系回调消息
+// 客户联系回调事件类型
+const (
+	// EventChangeExternalContact 企业客户事件
+	EventChangeExternalContact = "change_external_contact"
+	// EventChangeExternalChat 客户群事件
+	EventChangeExternalChat = "change_external_chat"
+	// EventChangeExternalTag 企业客户标签事件
+	EventChangeExternalTag = "change_external_tag"
+)
+
+// 企业客户事件的变更类型
+const (
+	// ChangeTypeAddExternalContact 添加企业客户
+	ChangeTypeAddExternalContact = "add_external_contact"
+	// ChangeTypeEditExternalContact 编辑企业客户
+	ChangeTypeEditExternalContact = "edit_external_contact"
+	// ChangeTypeAddHalfExternalContact 外部联系人免验证添加成员
+	ChangeTypeAddHalfExternalContact = "add_half_external_contact"
+	// ChangeTypeDelExternalContact 删除企业客户
+	ChangeTypeDelExternalContact = "del_external_contact"
+	// ChangeTypeDelFollowUser 删除跟进成员
+	ChangeTypeDelFollowUser = "del_follow_user"
+	// ChangeTypeTransferFail 客户接替失败
+	ChangeTypeTransferFail = "transfer_fail"
+)
+
+// 客户群事件与企业客户标签事件的变更类型
+const (
+	// ChangeTypeCreate 客户群创建、企业客户标签创建
+	ChangeTypeCreate = "create"
+	// ChangeTypeUpdate 客户群变更、企业客户标签变更
+	ChangeTypeUpdate = "update"
+	// ChangeTypeDismiss 客户群解散
+	ChangeTypeDismiss = "dismiss"
+	// ChangeTypeDelete 企业客户标签删除
+	ChangeTypeDelete = "delete"
+	// ChangeTypeShuffle 企业客户标签重排
+	ChangeTypeShuffle = "shuffle"
+)
+
+// 客户群变更事件的变更详情
+const (
+	// UpdateDetailAddMember 成员入群
+	UpdateDetailAddMember = "add_member"
+	// UpdateDetailDelMember 成员退群
+	UpdateDetailDelMember = "del_member"
+	// UpdateDetailChangeOwner 群主变更
+	UpdateDetailChangeOwner = "change_owner"
+	// UpdateDetailChangeName 群名变更
+	UpdateDetailChangeName = "change_name"
+	// UpdateDetailChangeNotice 群公告变更
+	UpdateDetailChangeNotice = "change_notice"
+)
+
+// 成员入群方式
+const (
+	// JoinSceneInvite 由成员邀请入群（直接邀请入群）
+	JoinSceneInvite = 1
+	// JoinSceneLink 由成员邀请入群（通过邀请链接入群）
+	JoinSceneLink = 2
+	// JoinSceneQrCode 通过扫描群二维码入群
+	JoinSceneQrCode = 3
+)
+
+// 成员退群方式
+const (
+	// QuitSceneSelf 自己退群
+	QuitSceneSelf = 0
+	// QuitSceneRemoved 群主/群管理员移出
+	QuitSceneRemoved = 1
+)
+
+// 企业客户标签事件的标签类型
+const (
+	// TagTypeTag 标签
+	TagTypeTag = "tag"
+	// TagTypeTagGroup 标签组
+	TagTypeTagGroup = "tag_group"
+)
+
+const (
+	// SourceDeleteByTransfer 删除企业客户事件中，表示客户被在职继承或离职继承而删除
+	SourceDeleteByTransfer = "DELETE_BY_TRANSFER"
+	// FailReasonCustomerRefused 客户接替失败原因：客户拒绝
+	FailReasonCustomerRefused = "customer_refused"
+	// FailReasonCustomerLimitExceed 客户接替失败原因：接替成员的客户数达到上限
+	FailReasonCustomerLimitExceed = "customer_limit_exceed"
+)
+
+// EventCallbackMessage 微信客户联系回调消息，包含企业客户、客户群与企业客户标签事件的全部字段，
+// 各字段仅在对应的Event与ChangeType下有值
 // https://developer.work.weixin.qq.com/document/path/92130
 type EventCallbackMessage struct {
-	ToUserName     string `json:"to_user_name"`
-	FromUserName   string `json:"from_user_name"`
-	CreateTime     int64  `json:"create_time"`
-	MsgType        string `json:"msg_type"`
-	Event          string `json:"event"`
-	ChangeType     string `json:"change_type"`
-	UserID         string `json:"user_id"`
-	ExternalUserID string `json:"external_user_id"`
-	State          string `json:"state"`
-	WelcomeCode    string `json:"welcome_code"`
+	ToUserName   string `xml:"ToUserName" json:"to_user_name"`
+	FromUserName string `xml:"FromUserName" json:"from_user_name"`
+	CreateTime   int64  `xml:"CreateTime" json:"create_time"`
+	MsgType      string `xml:"MsgType" json:"msg_type"`
+	Event        string `xml:"Event" json:"event"`            // 事件类型，见 EventChangeExternalContact 等常量
+	ChangeType   string `xml:"ChangeType" json:"change_type"` // 变更类型，见 ChangeTypeAddExternalContact 等常量
+
+	// 企业客户事件
+	UserID         string `xml:"UserID" json:"user_id"`                   // 企业服务人员的UserID
+	ExternalUserID string `xml:"ExternalUserID" json:"external_user_id"`  // 外部联系人的userid
+	State          string `xml:"State" json:"state"`                      // 添加此用户的「联系我」方式配置的state参数，或在获客链接中指定的customer_channel参数
+	WelcomeCode    string `xml:"WelcomeCode" json:"welcome_code"`         // 欢迎语code，可用于发送欢迎语
+	Source         string `xml:"Source" json:"source,omitempty"`          // 删除客户的操作来源，DELETE_BY_TRANSFER表示由于客户接替而被删除
+	FailReason     string `xml:"FailReason" json:"fail_reason,omitempty"` // 客户接替失败的原因，见 FailReasonCustomerRefused 等常量
+
+	// 客户群事件
+	ChatID        string   `xml:"ChatId" json:"chat_id,omitempty"`                     // 群ID
+	UpdateDetail  string   `xml:"UpdateDetail" json:"update_detail,omitempty"`         // 客户群变更详情，见 UpdateDetailAddMember 等常量
+	JoinScene     int      `xml:"JoinScene" json:"join_scene,omitempty"`               // 成员入群方式，仅UpdateDetail为add_member时有值
+	QuitScene     int      `xml:"QuitScene" json:"quit_scene,omitempty"`               // 成员退群方式，仅UpdateDetail为del_member时有值
+	MemChangeCnt  int      `xml:"MemChangeCnt" json:"mem_change_cnt,omitempty"`        // 本次入群或退群的成员数
+	MemChangeList []string `xml:"MemChangeList>Item" json:"mem_change_list,omitempty"` // 本次入群或退群的成员id列表
+	LastMemVer    string   `xml:"LastMemVer" json:"last_mem_ver,omitempty"`            // 变更前的群成员版本号
+	CurMemVer     string   `xml:"CurMemVer" json:"cur_mem_ver,omitempty"`              // 变更后的群成员版本号
+
+	// 企业客户标签事件
+	ID         string `xml:"Id" json:"id,omitempty"`                  // 标签或标签组的ID
+	TagType    string `xml:"TagType" json:"tag_type,omitempty"`       // 标签类型，见 TagTypeTag 等常量
+	StrategyID int    `xml:"StrategyId" json:"strategy_id,omitempty"` // 规则组id，规则组标签事件时有值
 }
 
 // GetCallbackMessage 获取联系客户回调事件中的消息内容
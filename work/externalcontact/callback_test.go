@@ -0,0 +1,50 @@
+package externalcontact
+
+import (
+	"encoding/xml"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventCallbackMessageUnmarshal(t *testing.T) {
+	var msg EventCallbackMessage
+	err := xml.Unmarshal([]byte(`<xml>
+<ToUserName><![CDATA[toUser]]></ToUserName>
+<FromUserName><![CDATA[sys]]></FromUserName>
+<CreateTime>1403610513</CreateTime>
+<MsgType><![CDATA[event]]></MsgType>
+<Event><![CDATA[change_external_chat]]></Event>
+<ChatId><![CDATA[CHAT_ID]]></ChatId>
+<ChangeType><![CDATA[update]]></ChangeType>
+<UpdateDetail><![CDATA[add_member]]></UpdateDetail>
+<JoinScene>1</JoinScene>
+<QuitScene>0</QuitScene>
+<MemChangeCnt>2</MemChangeCnt>
+<MemChangeList><Item>Jack</Item><Item>Rose</Item></MemChangeList>
+<LastMemVer>9c3f97c2ada667dfb5f6d03308d963e1</LastMemVer>
+<CurMemVer>71217227bbd112ecfe3a49c482195cb4</CurMemVer>
+</xml>`), &msg)
+	assert.Nil(t, err)
+	assert.Equal(t, EventChangeExternalChat, msg.Event)
+	assert.Equal(t, ChangeTypeUpdate, msg.ChangeType)
+	assert.Equal(t, "CHAT_ID", msg.ChatID)
+	assert.Equal(t, UpdateDetailAddMember, msg.UpdateDetail)
+	assert.Equal(t, JoinSceneInvite, msg.JoinScene)
+	assert.Equal(t, 2, msg.MemChangeCnt)
+	assert.Equal(t, []string{"Jack", "Rose"}, msg.MemChangeList)
+	assert.Equal(t, "71217227bbd112ecfe3a49c482195cb4", msg.CurMemVer)
+
+	msg = EventCallbackMessage{}
+	err = xml.Unmarshal([]byte(`<xml>
+<Event><![CDATA[change_external_tag]]></Event>
+<Id><![CDATA[TAG_ID]]></Id>
+<TagType><![CDATA[tag_group]]></TagType>
+<ChangeType><![CDATA[create]]></ChangeType>
+<StrategyId>1</StrategyId>
+</xml>`), &msg)
+	assert.Nil(t, err)
+	assert.Equal(t, "TAG_ID", msg.ID)
+	assert.Equal(t, TagTypeTagGroup, msg.TagType)
+	assert.Equal(t, 1, msg.StrategyID)
+}
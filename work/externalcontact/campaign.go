@@ -0,0 +1,340 @@
+package externalcontact
+
+import (
+	stdcontext "context"
+	"fmt"
+	"sort"
+	"time"
+)
+
+const (
+	// batchGetUserIDLimit 批量获取客户详情每次请求的成员数上限
+	batchGetUserIDLimit = 100
+	// batchGetPageLimit 批量获取客户详情每页返回的数量上限
+	batchGetPageLimit = 100
+	// groupMsgPageLimit 获取群发成员发送任务与客户发送结果每页返回的数量上限
+	groupMsgPageLimit = 1000
+	// msgTemplateCustomerLimit 创建企业群发每次指定的客户数上限
+	msgTemplateCustomerLimit = 10000
+)
+
+// 成员群发任务状态
+const (
+	// GroupMsgTaskUnsent 成员未发送
+	GroupMsgTaskUnsent = 0
+	// GroupMsgTaskSent 成员已发送
+	GroupMsgTaskSent = 2
+)
+
+// 客户群发执行结果
+const (
+	// GroupMsgSendUnsent 未发送
+	GroupMsgSendUnsent = 0
+	// GroupMsgSendSent 已发送
+	GroupMsgSendSent = 1
+	// GroupMsgSendNotFriend 因客户不是好友导致发送失败
+	GroupMsgSendNotFriend = 2
+	// GroupMsgSendReceived 因客户已经收到其他群发消息导致发送失败
+	GroupMsgSendReceived = 3
+)
+
+// CampaignAudience 群发的目标客户筛选条件
+type CampaignAudience struct {
+	FollowUsers []string  // 添加了客户的成员userid，必填；同一客户被多个成员添加时，只由排在前面的成员发送
+	TagIDs      []string  // 客户在该成员下至少有其中一个企业标签，为空时不按标签筛选
+	AddedAfter  time.Time // 成员添加客户的时间不早于该时间，零值时不限制
+	AddedBefore time.Time // 成员添加客户的时间早于该时间，零值时不限制
+}
+
+// CampaignRequest 创建群发活动请求
+type CampaignRequest struct {
+	Audience    CampaignAudience
+	Text        MsgText
+	Attachments []*Attachment
+	AllowSelect bool // 是否允许成员在待发送客户列表中重新选择
+}
+
+// Campaign 群发活动，每个成员对应一条或多条企业群发
+type Campaign struct {
+	Messages  []CampaignMessage `json:"messages"`
+	CreatedAt time.Time         `json:"created_at"`
+}
+
+// CampaignMessage 群发活动中为一个成员创建的企业群发
+type CampaignMessage struct {
+	MsgID           string   `json:"msgid"`
+	Sender          string   `json:"sender"`
+	ExternalUserIDs []string `json:"external_userid"`
+	FailList        []string `json:"fail_list,omitempty"` // 无效或无法发送的客户
+}
+
+// EmployeeProgress 成员的群发执行情况
+type EmployeeProgress struct {
+	MsgID     string `json:"msgid"`
+	UserID    string `json:"userid"`
+	Status    int    `json:"status"`    // 成员发送状态，见 GroupMsgTaskUnsent 等常量
+	SendTime  int    `json:"send_time"` // 成员发送时间
+	Customers int    `json:"customers"` // 客户数
+	Sent      int    `json:"sent"`      // 已发送的客户数
+	NotFriend int    `json:"not_friend"`
+	Received  int    `json:"received"` // 已收到其他群发消息的客户数
+	Pending   int    `json:"pending"`  // 未发送的客户数
+}
+
+// CampaignReport 群发活动执行报告
+type CampaignReport struct {
+	Employees        []EmployeeProgress `json:"employees"`
+	EmployeesSent    int                `json:"employees_sent"`
+	EmployeesPending int                `json:"employees_pending"`
+	Customers        int                `json:"customers"`
+	Sent             int                `json:"sent"`
+	NotFriend        int                `json:"not_friend"`
+	Received         int                `json:"received"`
+	Pending          int                `json:"pending"`
+	GeneratedAt      time.Time          `json:"generated_at"`
+}
+
+// Summary 返回报告的摘要
+func (r *CampaignReport) Summary() string {
+	return fmt.Sprintf("成员%d人，已发送%d人，未发送%d人；客户%d人，已送达%d人，非好友%d人，已收到其他群发%d人，未发送%d人",
+		len(r.Employees), r.EmployeesSent, r.EmployeesPending, r.Customers, r.Sent, r.NotFriend, r.Received, r.Pending)
+}
+
+// Laggards 返回尚未发送群发的成员
+func (r *CampaignReport) Laggards() []EmployeeProgress {
+	var laggards []EmployeeProgress
+	for _, employee := range r.Employees {
+		if employee.Status == GroupMsgTaskUnsent {
+			laggards = append(laggards, employee)
+		}
+	}
+	return laggards
+}
+
+// CampaignManager 客户群发活动管理，按条件筛选客户后为每个成员创建企业群发，并跟踪执行结果、提醒未发送的成员
+type CampaignManager struct {
+	client *Client
+	page   *PageOptions
+}
+
+// NewCampaignManager 创建群发活动管理，page为分页拉取的配置，可为nil；每页数量超过接口上限时按上限请求
+func NewCampaignManager(client *Client, page *PageOptions) *CampaignManager {
+	return &CampaignManager{client: client, page: page}
+}
+
+// ResolveAudience 按筛选条件获取目标客户，返回成员userid到客户external_userid列表的映射
+func (m *CampaignManager) ResolveAudience(ctx stdcontext.Context, audience CampaignAudience) (map[string][]string, error) {
+	if len(audience.FollowUsers) == 0 {
+		return nil, fmt.Errorf("audience follow users are required")
+	}
+	tags := make(map[string]bool, len(audience.TagIDs))
+	for _, tagID := range audience.TagIDs {
+		tags[tagID] = true
+	}
+	order := make(map[string]int, len(audience.FollowUsers))
+	for i, userID := range audience.FollowUsers {
+		order[userID] = i
+	}
+
+	// 客户在多个成员下时分配给排在前面的成员
+	owners := make(map[string]string)
+	for start := 0; start < len(audience.FollowUsers); start += batchGetUserIDLimit {
+		end := start + batchGetUserIDLimit
+		if end > len(audience.FollowUsers) {
+			end = len(audience.FollowUsers)
+		}
+		req := BatchGetExternalUserDetailsRequest{UserIDList: audience.FollowUsers[start:end], Limit: m.page.limitedPageSize(batchGetPageLimit)}
+		err := paginate(ctx, m.page, "", func(cursor string) (string, error) {
+			req.Cursor = cursor
+			result, err := m.client.BatchGetExternalUserDetails(req)
+			if err != nil {
+				return "", err
+			}
+			for _, item := range result.ExternalContactList {
+				if !audience.match(item.FollowInfo, tags) {
+					continue
+				}
+				externalUserID := item.ExternalContact.ExternalUserID
+				owner, ok := owners[externalUserID]
+				if !ok || order[item.FollowInfo.UserID] < order[owner] {
+					owners[externalUserID] = item.FollowInfo.UserID
+				}
+			}
+			return result.NextCursor, nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	customers := make(map[string][]string)
+	for externalUserID, userID := range owners {
+		customers[userID] = append(customers[userID], externalUserID)
+	}
+	for _, list := range customers {
+		sort.Strings(list)
+	}
+	return customers, nil
+}
+
+// match 客户与成员的关系是否满足筛选条件
+func (a CampaignAudience) match(follow FollowInfo, tags map[string]bool) bool {
+	addedAt := time.Unix(follow.CreateTime, 0)
+	if !a.AddedAfter.IsZero() && addedAt.Before(a.AddedAfter) {
+		return false
+	}
+	if !a.AddedBefore.IsZero() && !addedAt.Before(a.AddedBefore) {
+		return false
+	}
+	if len(tags) == 0 {
+		return true
+	}
+	for _, tagID := range follow.TagID {
+		if tags[tagID] {
+			return true
+		}
+	}
+	return false
+}
+
+// Create 筛选客户并为每个成员创建企业群发；创建过程中出错时返回已创建的部分，可用于取消
+func (m *CampaignManager) Create(ctx stdcontext.Context, req CampaignRequest) (*Campaign, error) {
+	customers, err := m.ResolveAudience(ctx, req.Audience)
+	if err != nil {
+		return nil, err
+	}
+	campaign := &Campaign{CreatedAt: time.Now()}
+	for _, sender := range req.Audience.FollowUsers {
+		list := customers[sender]
+		for start := 0; start < len(list); start += msgTemplateCustomerLimit {
+			if err = ctx.Err(); err != nil {
+				return campaign, err
+			}
+			end := start + msgTemplateCustomerLimit
+			if end > len(list) {
+				end = len(list)
+			}
+			result, err := m.client.AddMsgTemplate(&AddMsgTemplateRequest{
+				ChatType:       "single",
+				ExternalUserID: list[start:end],
+				Sender:         sender,
+				Text:           req.Text,
+				Attachments:    req.Attachments,
+				AllowSelect:    req.AllowSelect,
+			})
+			if err != nil {
+				return campaign, fmt.Errorf("create group message for %s: %w", sender, err)
+			}
+			campaign.Messages = append(campaign.Messages, CampaignMessage{
+				MsgID:           result.MsgID,
+				Sender:          sender,
+				ExternalUserIDs: list[start:end],
+				FailList:        result.FailList,
+			})
+		}
+	}
+	return campaign, nil
+}
+
+// Track 拉取每个成员的发送任务与客户发送结果，生成执行报告
+func (m *CampaignManager) Track(ctx stdcontext.Context, campaign *Campaign) (*CampaignReport, error) {
+	report := &CampaignReport{GeneratedAt: time.Now()}
+	for _, message := range campaign.Messages {
+		progress := EmployeeProgress{MsgID: message.MsgID, UserID: message.Sender}
+		taskReq := &GetGroupMsgTaskRequest{MsgID: message.MsgID, Limit: m.page.limitedPageSize(groupMsgPageLimit)}
+		err := paginate(ctx, m.page, "", func(cursor string) (string, error) {
+			taskReq.Cursor = cursor
+			result, err := m.client.GetGroupMsgTask(taskReq)
+			if err != nil {
+				return "", err
+			}
+			for _, task := range result.TaskList {
+				if task.UserID == message.Sender {
+					progress.Status, progress.SendTime = task.Status, task.SendTime
+				}
+			}
+			return result.NextCursor, nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("get group message task %s: %w", message.MsgID, err)
+		}
+
+		sendReq := &GetGroupMsgSendResultRequest{MsgID: message.MsgID, UserID: message.Sender, Limit: m.page.limitedPageSize(groupMsgPageLimit)}
+		err = paginate(ctx, m.page, "", func(cursor string) (string, error) {
+			sendReq.Cursor = cursor
+			result, err := m.client.GetGroupMsgSendResult(sendReq)
+			if err != nil {
+				return "", err
+			}
+			for _, send := range result.SendList {
+				progress.add(send.Status)
+			}
+			return result.NextCursor, nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("get group message send result %s: %w", message.MsgID, err)
+		}
+		report.add(progress)
+	}
+	return report, nil
+}
+
+// add 累计一个客户的发送结果
+func (p *EmployeeProgress) add(status int) {
+	p.Customers++
+	switch status {
+	case GroupMsgSendSent:
+		p.Sent++
+	case GroupMsgSendNotFriend:
+		p.NotFriend++
+	case GroupMsgSendReceived:
+		p.Received++
+	default:
+		p.Pending++
+	}
+}
+
+// add 累计一个成员的执行情况
+func (r *CampaignReport) add(progress EmployeeProgress) {
+	r.Employees = append(r.Employees, progress)
+	if progress.Status == GroupMsgTaskSent {
+		r.EmployeesSent++
+	} else {
+		r.EmployeesPending++
+	}
+	r.Customers += progress.Customers
+	r.Sent += progress.Sent
+	r.NotFriend += progress.NotFriend
+	r.Received += progress.Received
+	r.Pending += progress.Pending
+}
+
+// Remind 提醒报告中尚未发送的成员，返回已提醒的msgid；每条群发24小时内只能提醒一次，最多提醒三次，
+// 单条群发提醒失败不影响其他群发
+func (m *CampaignManager) Remind(report *CampaignReport) ([]string, error) {
+	var (
+		reminded []string
+		errs     []error
+	)
+	for _, employee := range report.Laggards() {
+		if err := m.client.RemindGroupMsgSend(&RemindGroupMsgSendRequest{MsgID: employee.MsgID}); err != nil {
+			errs = append(errs, fmt.Errorf("remind %s: %w", employee.UserID, err))
+			continue
+		}
+		reminded = append(reminded, employee.MsgID)
+	}
+	if len(errs) > 0 {
+		return reminded, fmt.Errorf("%d reminders failed, first error: %w", len(errs), errs[0])
+	}
+	return reminded, nil
+}
+
+// Cancel 停止群发活动中的全部企业群发
+func (m *CampaignManager) Cancel(campaign *Campaign) error {
+	for _, message := range campaign.Messages {
+		if err := m.client.CancelGroupMsgSend(&CancelGroupMsgSendRequest{MsgID: message.MsgID}); err != nil {
+			return fmt.Errorf("cancel group message %s: %w", message.MsgID, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package externalcontact
+
+import (
+	stdcontext "context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/h2non/gock.v1"
+)
+
+func customerDetail(externalUserID, userID string, addedAt time.Time, tags ...string) map[string]interface{} {
+	return map[string]interface{}{
+		"external_contact": map[string]interface{}{"external_userid": externalUserID},
+		"follow_info":      map[string]interface{}{"userid": userID, "createtime": addedAt.Unix(), "tag_id": tags},
+	}
+}
+
+func TestCampaignManager(t *testing.T) {
+	defer gock.Off()
+	now := time.Now()
+	// 配置的每页数量超过批量获取客户详情的上限100，但不超过群发接口的上限1000
+	manager := NewCampaignManager(newTestClient(), &PageOptions{PageSize: 500, Interval: -1})
+
+	// wm1同时被两个成员添加时由排在前面的zhangsan发送，wm3没有指定标签，wm4添加时间早于筛选条件
+	mockExternalContactAPI("batch/get_by_user", `"userid_list":\["zhangsan","lisi"\],"cursor":"","limit":100`, map[string]interface{}{
+		"next_cursor":           "c1",
+		"external_contact_list": []interface{}{customerDetail("wm1", "lisi", now, "vip"), customerDetail("wm1", "zhangsan", now, "vip")},
+	})
+	mockExternalContactAPI("batch/get_by_user", `"cursor":"c1","limit":100`, map[string]interface{}{
+		"external_contact_list": []interface{}{
+			customerDetail("wm2", "lisi", now, "vip"), customerDetail("wm3", "lisi", now), customerDetail("wm4", "lisi", now.Add(-48*time.Hour), "vip"),
+		},
+	})
+	mockExternalContactAPI("add_msg_template", `"chat_type":"single","external_userid":\["wm1"\],"sender":"zhangsan","text":\{"content":"新品上市"\}`,
+		map[string]interface{}{"msgid": "msg_zhangsan"})
+	mockExternalContactAPI("add_msg_template", `"chat_type":"single","external_userid":\["wm2"\],"sender":"lisi"`,
+		map[string]interface{}{"msgid": "msg_lisi", "fail_list": []string{"wm2"}})
+	campaign, err := manager.Create(stdcontext.Background(), CampaignRequest{
+		Audience: CampaignAudience{FollowUsers: []string{"zhangsan", "lisi"}, TagIDs: []string{"vip"}, AddedAfter: now.Add(-time.Hour)},
+		Text:     MsgText{Content: "新品上市"},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []CampaignMessage{
+		{MsgID: "msg_zhangsan", Sender: "zhangsan", ExternalUserIDs: []string{"wm1"}},
+		{MsgID: "msg_lisi", Sender: "lisi", ExternalUserIDs: []string{"wm2"}, FailList: []string{"wm2"}},
+	}, campaign.Messages)
+	assert.True(t, gock.IsDone())
+
+	mockExternalContactAPI("get_groupmsg_task", `"msgid":"msg_zhangsan","limit":500`, map[string]interface{}{
+		"task_list": []map[string]interface{}{{"userid": "zhangsan", "status": GroupMsgTaskSent, "send_time": now.Unix()}},
+	})
+	mockExternalContactAPI("get_groupmsg_send_result", `"msgid":"msg_zhangsan","userid":"zhangsan","limit":500`, map[string]interface{}{
+		"send_list": []map[string]interface{}{{"external_userid": "wm1", "userid": "zhangsan", "status": GroupMsgSendSent}},
+	})
+	mockExternalContactAPI("get_groupmsg_task", `"msgid":"msg_lisi","limit":500`, map[string]interface{}{
+		"task_list": []map[string]interface{}{{"userid": "lisi", "status": GroupMsgTaskUnsent}},
+	})
+	mockExternalContactAPI("get_groupmsg_send_result", `"msgid":"msg_lisi","userid":"lisi","limit":500`, map[string]interface{}{
+		"send_list": []map[string]interface{}{{"external_userid": "wm2", "userid": "lisi", "status": GroupMsgSendUnsent}},
+	})
+	report, err := manager.Track(stdcontext.Background(), campaign)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, report.EmployeesSent)
+	assert.Equal(t, 1, report.EmployeesPending)
+	assert.Equal(t, 2, report.Customers)
+	assert.Equal(t, 1, report.Sent)
+	assert.Equal(t, 1, report.Pending)
+	assert.Equal(t, "成员2人，已发送1人，未发送1人；客户2人，已送达1人，非好友0人，已收到其他群发0人，未发送1人", report.Summary())
+
+	mockExternalContactAPI("remind_groupmsg_send", `"msgid":"msg_lisi"`, map[string]interface{}{})
+	reminded, err := manager.Remind(report)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"msg_lisi"}, reminded)
+
+	mockExternalContactAPI("cancel_groupmsg_send", `"msgid":"msg_zhangsan"`, map[string]interface{}{})
+	mockExternalContactAPI("cancel_groupmsg_send", `"msgid":"msg_lisi"`, map[string]interface{}{})
+	assert.Nil(t, manager.Cancel(campaign))
+	assert.True(t, gock.IsDone())
+}
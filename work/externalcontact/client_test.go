@@ -0,0 +1,22 @@
+package externalcontact
+
+import (
+	"gopkg.in/h2non/gock.v1"
+
+	"github.com/northseadl/wechat/v2/internal/worktest"
+)
+
+// newTestClient 创建使用内存缓存的客户联系客户端，并模拟获取access_token
+func newTestClient() *Client {
+	return NewClient(worktest.NewContext())
+}
+
+// mockExternalContactAPI 模拟客户联系的POST接口，body为请求体需要匹配的正则，reply中自动补充errcode
+func mockExternalContactAPI(path string, body string, reply map[string]interface{}) {
+	reply["errcode"] = 0
+	gock.New("https://qyapi.weixin.qq.com").
+		Post("/cgi-bin/externalcontact/" + path).
+		BodyString(body).
+		Reply(200).
+		JSON(reply)
+}
@@ -0,0 +1,360 @@
+package externalcontact
+
+import (
+	stdcontext "context"
+	"time"
+)
+
+// defaultPageInterval 默认的分页请求间隔
+const defaultPageInterval = 100 * time.Millisecond
+
+// PageOptions 分页遍历配置
+type PageOptions struct {
+	PageSize int           // 每页数量，为0时使用请求参数中的limit
+	Interval time.Duration // 两次分页请求之间的间隔，用于控制调用频率，为0时使用默认的100ms，小于0时不等待
+}
+
+// pageSize 返回配置的每页数量，未配置时返回fallback
+func (o *PageOptions) pageSize(fallback int) int {
+	if o == nil || o.PageSize <= 0 {
+		return fallback
+	}
+	return o.PageSize
+}
+
+// limitedPageSize 返回配置的每页数量，未配置或超过接口上限limit时返回limit
+func (o *PageOptions) limitedPageSize(limit int) int {
+	if size := o.pageSize(limit); size < limit {
+		return size
+	}
+	return limit
+}
+
+// interval 返回两次分页请求之间的间隔
+func (o *PageOptions) interval() time.Duration {
+	if o == nil || o.Interval == 0 {
+		return defaultPageInterval
+	}
+	if o.Interval < 0 {
+		return 0
+	}
+	return o.Interval
+}
+
+// paginate 从cursor开始逐页调用fetch，直到fetch返回的next_cursor为空、fetch返回错误或ctx结束
+func paginate(ctx stdcontext.Context, opts *PageOptions, cursor string, fetch func(cursor string) (string, error)) error {
+	interval := opts.interval()
+	for first := true; ; first = false {
+		if !first && interval > 0 {
+			timer := time.NewTimer(interval)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return ctx.Err()
+			case <-timer.C:
+			}
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		next, err := fetch(cursor)
+		if err != nil {
+			return err
+		}
+		if next == "" {
+			return nil
+		}
+		cursor = next
+	}
+}
+
+// RangeContactList 逐页获取已服务的外部联系人，每获取一页调用一次fn，fn返回错误时停止遍历并返回该错误
+func (r *Client) RangeContactList(ctx stdcontext.Context, req *ContactListRequest, opts *PageOptions, fn func(list []ContactInfo) error) error {
+	pageReq := *req
+	pageReq.Limit = opts.pageSize(pageReq.Limit)
+	return paginate(ctx, opts, pageReq.Cursor, func(cursor string) (string, error) {
+		pageReq.Cursor = cursor
+		result, err := r.GetContactList(&pageReq)
+		if err != nil {
+			return "", err
+		}
+		return result.NextCursor, fn(result.InfoList)
+	})
+}
+
+// RangeContactWay 逐页获取企业已配置的「联系我」列表
+func (r *Client) RangeContactWay(ctx stdcontext.Context, req *ListContactWayRequest, opts *PageOptions, fn func(list []*ContactWayForList) error) error {
+	pageReq := *req
+	pageReq.Limit = opts.pageSize(pageReq.Limit)
+	return paginate(ctx, opts, pageReq.Cursor, func(cursor string) (string, error) {
+		pageReq.Cursor = cursor
+		result, err := r.ListContactWay(&pageReq)
+		if err != nil {
+			return "", err
+		}
+		return result.NextCursor, fn(result.ContactWay)
+	})
+}
+
+// RangeLink 逐页获取获客链接列表
+func (r *Client) RangeLink(ctx stdcontext.Context, req *ListLinkRequest, opts *PageOptions, fn func(linkIDList []string) error) error {
+	pageReq := *req
+	pageReq.Limit = opts.pageSize(pageReq.Limit)
+	return paginate(ctx, opts, pageReq.Cursor, func(cursor string) (string, error) {
+		pageReq.Cursor = cursor
+		result, err := r.ListLink(&pageReq)
+		if err != nil {
+			return "", err
+		}
+		return result.NextCursor, fn(result.LinkIDList)
+	})
+}
+
+// RangeCustomerInfoWithCustomerAcquisitionLink 逐页获取由获客链接添加的客户信息
+func (r *Client) RangeCustomerInfoWithCustomerAcquisitionLink(ctx stdcontext.Context, req *GetCustomerInfoWithCustomerAcquisitionLinkRequest, opts *PageOptions, fn func(list []CustomerList) error) error {
+	pageReq := *req
+	pageReq.Limit = int64(opts.pageSize(int(pageReq.Limit)))
+	return paginate(ctx, opts, pageReq.Cursor, func(cursor string) (string, error) {
+		pageReq.Cursor = cursor
+		result, err := r.GetCustomerInfoWithCustomerAcquisitionLink(&pageReq)
+		if err != nil {
+			return "", err
+		}
+		return result.NextCursor, fn(result.CustomerList)
+	})
+}
+
+// RangeExternalUserDetail 逐页获取外部联系人详情，外部联系人的跟进人较多时接口会分页返回follow_user，每页调用一次fn
+func (r *Client) RangeExternalUserDetail(ctx stdcontext.Context, externalUserID string, opts *PageOptions, fn func(contact ExternalUser, followUsers []FollowUser) error) error {
+	return paginate(ctx, opts, "", func(cursor string) (string, error) {
+		result, err := r.GetExternalUserDetail(externalUserID, cursor)
+		if err != nil {
+			return "", err
+		}
+		return result.NextCursor, fn(result.ExternalContact, result.FollowUser)
+	})
+}
+
+// RangeExternalUserDetails 逐页批量获取外部联系人详情
+func (r *Client) RangeExternalUserDetails(ctx stdcontext.Context, req BatchGetExternalUserDetailsRequest, opts *PageOptions, fn func(list []ExternalUserForBatch) error) error {
+	req.Limit = opts.pageSize(req.Limit)
+	return paginate(ctx, opts, req.Cursor, func(cursor string) (string, error) {
+		req.Cursor = cursor
+		result, err := r.BatchGetExternalUserDetails(req)
+		if err != nil {
+			return "", err
+		}
+		return result.NextCursor, fn(result.ExternalContactList)
+	})
+}
+
+// RangeCustomerStrategy 逐页获取客户联系规则组列表
+func (r *Client) RangeCustomerStrategy(ctx stdcontext.Context, req *ListCustomerStrategyRequest, opts *PageOptions, fn func(list []StrategyID) error) error {
+	pageReq := *req
+	pageReq.Limit = opts.pageSize(pageReq.Limit)
+	return paginate(ctx, opts, pageReq.Cursor, func(cursor string) (string, error) {
+		pageReq.Cursor = cursor
+		result, err := r.ListCustomerStrategy(&pageReq)
+		if err != nil {
+			return "", err
+		}
+		return result.NextCursor, fn(result.Strategy)
+	})
+}
+
+// RangeCustomerStrategyRange 逐页获取客户联系规则组管理范围
+func (r *Client) RangeCustomerStrategyRange(ctx stdcontext.Context, req *GetRangeCustomerStrategyRequest, opts *PageOptions, fn func(list []Range) error) error {
+	pageReq := *req
+	pageReq.Limit = opts.pageSize(pageReq.Limit)
+	return paginate(ctx, opts, pageReq.Cursor, func(cursor string) (string, error) {
+		pageReq.Cursor = cursor
+		result, err := r.GetRangeCustomerStrategy(&pageReq)
+		if err != nil {
+			return "", err
+		}
+		return result.NextCursor, fn(result.Range)
+	})
+}
+
+// RangeGroupChatList 逐页获取客户群列表
+func (r *Client) RangeGroupChatList(ctx stdcontext.Context, req *GroupChatListRequest, opts *PageOptions, fn func(list []GroupChatList) error) error {
+	pageReq := *req
+	pageReq.Limit = opts.pageSize(pageReq.Limit)
+	return paginate(ctx, opts, pageReq.Cursor, func(cursor string) (string, error) {
+		pageReq.Cursor = cursor
+		result, err := r.GetGroupChatList(&pageReq)
+		if err != nil {
+			return "", err
+		}
+		return result.NextCursor, fn(result.GroupChatList)
+	})
+}
+
+// RangeMomentList 逐页获取企业全部的发表列表
+func (r *Client) RangeMomentList(ctx stdcontext.Context, req *GetMomentListRequest, opts *PageOptions, fn func(list []MomentItem) error) error {
+	pageReq := *req
+	pageReq.Limit = opts.pageSize(pageReq.Limit)
+	return paginate(ctx, opts, pageReq.Cursor, func(cursor string) (string, error) {
+		pageReq.Cursor = cursor
+		result, err := r.GetMomentList(&pageReq)
+		if err != nil {
+			return "", err
+		}
+		return result.NextCursor, fn(result.MomentList)
+	})
+}
+
+// RangeMomentTask 逐页获取客户朋友圈企业发表的列表
+func (r *Client) RangeMomentTask(ctx stdcontext.Context, req *GetMomentTaskRequest, opts *PageOptions, fn func(list []MomentTask) error) error {
+	pageReq := *req
+	pageReq.Limit = opts.pageSize(pageReq.Limit)
+	return paginate(ctx, opts, pageReq.Cursor, func(cursor string) (string, error) {
+		pageReq.Cursor = cursor
+		result, err := r.GetMomentTask(&pageReq)
+		if err != nil {
+			return "", err
+		}
+		return result.NextCursor, fn(result.TaskList)
+	})
+}
+
+// RangeMomentCustomerList 逐页获取客户朋友圈发表时选择的可见范围
+func (r *Client) RangeMomentCustomerList(ctx stdcontext.Context, req *GetMomentCustomerListRequest, opts *PageOptions, fn func(list []MomentCustomer) error) error {
+	pageReq := *req
+	pageReq.Limit = opts.pageSize(pageReq.Limit)
+	return paginate(ctx, opts, pageReq.Cursor, func(cursor string) (string, error) {
+		pageReq.Cursor = cursor
+		result, err := r.GetMomentCustomerList(&pageReq)
+		if err != nil {
+			return "", err
+		}
+		return result.NextCursor, fn(result.CustomerList)
+	})
+}
+
+// RangeMomentSendResult 逐页获取客户朋友圈发表后的可见客户列表
+func (r *Client) RangeMomentSendResult(ctx stdcontext.Context, req *GetMomentSendResultRequest, opts *PageOptions, fn func(list []MomentSendCustomer) error) error {
+	pageReq := *req
+	pageReq.Limit = opts.pageSize(pageReq.Limit)
+	return paginate(ctx, opts, pageReq.Cursor, func(cursor string) (string, error) {
+		pageReq.Cursor = cursor
+		result, err := r.GetMomentSendResult(&pageReq)
+		if err != nil {
+			return "", err
+		}
+		return result.NextCursor, fn(result.CustomerList)
+	})
+}
+
+// RangeMomentStrategy 逐页获取客户朋友圈规则组列表
+func (r *Client) RangeMomentStrategy(ctx stdcontext.Context, req *ListMomentStrategyRequest, opts *PageOptions, fn func(list []MomentStrategyID) error) error {
+	pageReq := *req
+	pageReq.Limit = opts.pageSize(pageReq.Limit)
+	return paginate(ctx, opts, pageReq.Cursor, func(cursor string) (string, error) {
+		pageReq.Cursor = cursor
+		result, err := r.ListMomentStrategy(&pageReq)
+		if err != nil {
+			return "", err
+		}
+		return result.NextCursor, fn(result.Strategy)
+	})
+}
+
+// RangeMomentStrategyRange 逐页获取客户朋友圈规则组管理范围
+func (r *Client) RangeMomentStrategyRange(ctx stdcontext.Context, req *GetRangeMomentStrategyRequest, opts *PageOptions, fn func(list []RangeMomentStrategy) error) error {
+	pageReq := *req
+	pageReq.Limit = opts.pageSize(pageReq.Limit)
+	return paginate(ctx, opts, pageReq.Cursor, func(cursor string) (string, error) {
+		pageReq.Cursor = cursor
+		result, err := r.GetRangeMomentStrategy(&pageReq)
+		if err != nil {
+			return "", err
+		}
+		return result.NextCursor, fn(result.Range)
+	})
+}
+
+// RangeGroupMsgListV2 逐页获取群发记录列表
+func (r *Client) RangeGroupMsgListV2(ctx stdcontext.Context, req *GetGroupMsgListV2Request, opts *PageOptions, fn func(list []*GroupMsg) error) error {
+	pageReq := *req
+	pageReq.Limit = opts.pageSize(pageReq.Limit)
+	return paginate(ctx, opts, pageReq.Cursor, func(cursor string) (string, error) {
+		pageReq.Cursor = cursor
+		result, err := r.GetGroupMsgListV2(&pageReq)
+		if err != nil {
+			return "", err
+		}
+		return result.NextCursor, fn(result.GroupMsgList)
+	})
+}
+
+// RangeGroupMsgTask 逐页获取群发成员发送任务列表
+func (r *Client) RangeGroupMsgTask(ctx stdcontext.Context, req *GetGroupMsgTaskRequest, opts *PageOptions, fn func(list []*Task) error) error {
+	pageReq := *req
+	pageReq.Limit = opts.pageSize(pageReq.Limit)
+	return paginate(ctx, opts, pageReq.Cursor, func(cursor string) (string, error) {
+		pageReq.Cursor = cursor
+		result, err := r.GetGroupMsgTask(&pageReq)
+		if err != nil {
+			return "", err
+		}
+		return result.NextCursor, fn(result.TaskList)
+	})
+}
+
+// RangeGroupMsgSendResult 逐页获取企业群发成员执行结果
+func (r *Client) RangeGroupMsgSendResult(ctx stdcontext.Context, req *GetGroupMsgSendResultRequest, opts *PageOptions, fn func(list []*Send) error) error {
+	pageReq := *req
+	pageReq.Limit = opts.pageSize(pageReq.Limit)
+	return paginate(ctx, opts, pageReq.Cursor, func(cursor string) (string, error) {
+		pageReq.Cursor = cursor
+		result, err := r.GetGroupMsgSendResult(&pageReq)
+		if err != nil {
+			return "", err
+		}
+		return result.NextCursor, fn(result.SendList)
+	})
+}
+
+// RangeTransferResult 逐页查询在职成员客户接替状态，接口不支持指定每页数量
+func (r *Client) RangeTransferResult(ctx stdcontext.Context, req *TransferResultRequest, opts *PageOptions, fn func(list []TransferResultItem) error) error {
+	pageReq := *req
+	return paginate(ctx, opts, pageReq.Cursor, func(cursor string) (string, error) {
+		pageReq.Cursor = cursor
+		result, err := r.TransferResult(&pageReq)
+		if err != nil {
+			return "", err
+		}
+		return result.NextCursor, fn(result.Customer)
+	})
+}
+
+// RangeUnassignedList 逐页获取待分配的离职成员列表
+func (r *Client) RangeUnassignedList(ctx stdcontext.Context, req *GetUnassignedListRequest, opts *PageOptions, fn func(list []UnassignedListInfo) error) error {
+	pageReq := *req
+	pageReq.PageSize = opts.pageSize(pageReq.PageSize)
+	return paginate(ctx, opts, pageReq.Cursor, func(cursor string) (string, error) {
+		pageReq.Cursor = cursor
+		result, err := r.GetUnassignedList(&pageReq)
+		if err != nil {
+			return "", err
+		}
+		if err = fn(result.Info); err != nil || result.IsLast {
+			return "", err
+		}
+		return result.NextCursor, nil
+	})
+}
+
+// RangeResignedTransferResult 逐页查询离职成员客户接替状态，接口不支持指定每页数量
+func (r *Client) RangeResignedTransferResult(ctx stdcontext.Context, req *ResignedTransferResultRequest, opts *PageOptions, fn func(list []TransferResultItem) error) error {
+	pageReq := *req
+	return paginate(ctx, opts, pageReq.Cursor, func(cursor string) (string, error) {
+		pageReq.Cursor = cursor
+		result, err := r.ResignedTransferResult(&pageReq)
+		if err != nil {
+			return "", err
+		}
+		return result.NextCursor, fn(result.Customer)
+	})
+}
@@ -0,0 +1,109 @@
+package externalcontact
+
+import (
+	stdcontext "context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/h2non/gock.v1"
+)
+
+func TestPaginate(t *testing.T) {
+	pages := map[string]string{"": "a", "a": "b", "b": ""}
+	var cursors []string
+	err := paginate(stdcontext.Background(), &PageOptions{Interval: -1}, "", func(cursor string) (string, error) {
+		cursors = append(cursors, cursor)
+		return pages[cursor], nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"", "a", "b"}, cursors)
+
+	stop := errors.New("stop")
+	err = paginate(stdcontext.Background(), &PageOptions{Interval: -1}, "", func(cursor string) (string, error) {
+		return "next", stop
+	})
+	assert.Equal(t, stop, err)
+}
+
+func TestPaginateCancel(t *testing.T) {
+	ctx, cancel := stdcontext.WithCancel(stdcontext.Background())
+	var calls int
+	err := paginate(ctx, &PageOptions{Interval: time.Hour}, "", func(cursor string) (string, error) {
+		calls++
+		cancel()
+		return "next", nil
+	})
+	assert.Equal(t, stdcontext.Canceled, err)
+	assert.Equal(t, 1, calls)
+}
+
+func TestPageOptions(t *testing.T) {
+	var opts *PageOptions
+	assert.Equal(t, 100, opts.pageSize(100))
+	assert.Equal(t, 100, opts.limitedPageSize(100))
+	assert.Equal(t, defaultPageInterval, opts.interval())
+
+	opts = &PageOptions{PageSize: 1000, Interval: -1}
+	assert.Equal(t, 1000, opts.pageSize(100))
+	assert.Equal(t, 100, opts.limitedPageSize(100))
+	assert.Equal(t, 1000, opts.limitedPageSize(1000))
+	assert.Equal(t, time.Duration(0), opts.interval())
+}
+
+func TestRangeUnassignedList(t *testing.T) {
+	defer gock.Off()
+	client := newTestClient()
+	// 离职成员列表以is_last判断最后一页，最后一页返回的next_cursor不再请求
+	mockExternalContactAPI("get_unassigned_list", `"cursor":"","page_size":500`, map[string]interface{}{
+		"info": []map[string]interface{}{{"handover_userid": "zhangsan", "external_userid": "wm1"}}, "is_last": false, "next_cursor": "c1",
+	})
+	mockExternalContactAPI("get_unassigned_list", `"cursor":"c1","page_size":500`, map[string]interface{}{
+		"info": []map[string]interface{}{{"handover_userid": "zhangsan", "external_userid": "wm2"}}, "is_last": true, "next_cursor": "c2",
+	})
+	var externalUserIDs []string
+	err := client.RangeUnassignedList(stdcontext.Background(), &GetUnassignedListRequest{}, &PageOptions{PageSize: 500, Interval: -1},
+		func(list []UnassignedListInfo) error {
+			for _, info := range list {
+				externalUserIDs = append(externalUserIDs, info.ExternalUserID)
+			}
+			return nil
+		})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"wm1", "wm2"}, externalUserIDs)
+	assert.True(t, gock.IsDone())
+}
+
+func TestRangeExternalUserDetails(t *testing.T) {
+	defer gock.Off()
+	client := newTestClient()
+	mockExternalContactAPI("batch/get_by_user", `"userid_list":\["zhangsan"\],"cursor":"","limit":50`, map[string]interface{}{
+		"external_contact_list": []map[string]interface{}{{"external_contact": map[string]interface{}{"external_userid": "wm1"}}}, "next_cursor": "c1",
+	})
+	mockExternalContactAPI("batch/get_by_user", `"userid_list":\["zhangsan"\],"cursor":"c1","limit":50`, map[string]interface{}{
+		"external_contact_list": []map[string]interface{}{{"external_contact": map[string]interface{}{"external_userid": "wm2"}}},
+	})
+	// 请求按值传入，翻页不修改调用方的请求
+	req := BatchGetExternalUserDetailsRequest{UserIDList: []string{"zhangsan"}}
+	var externalUserIDs []string
+	err := client.RangeExternalUserDetails(stdcontext.Background(), req, &PageOptions{PageSize: 50, Interval: -1},
+		func(list []ExternalUserForBatch) error {
+			for _, item := range list {
+				externalUserIDs = append(externalUserIDs, item.ExternalContact.ExternalUserID)
+			}
+			return nil
+		})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"wm1", "wm2"}, externalUserIDs)
+	assert.Equal(t, BatchGetExternalUserDetailsRequest{UserIDList: []string{"zhangsan"}}, req)
+	assert.True(t, gock.IsDone())
+
+	// fn返回错误时不再请求下一页
+	stop := errors.New("stop")
+	mockExternalContactAPI("batch/get_by_user", `"cursor":""`, map[string]interface{}{"next_cursor": "c1"})
+	err = client.RangeExternalUserDetails(stdcontext.Background(), req, &PageOptions{Interval: -1},
+		func(list []ExternalUserForBatch) error { return stop })
+	assert.Equal(t, stop, err)
+	assert.True(t, gock.IsDone())
+}
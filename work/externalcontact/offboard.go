@@ -0,0 +1,404 @@
+package externalcontact
+
+import (
+	stdcontext "context"
+	"fmt"
+	"time"
+)
+
+const (
+	// transferCustomerLimit 分配客户每次指定的客户数上限
+	transferCustomerLimit = 100
+	// transferGroupChatLimit 分配客户群每次指定的群数上限
+	transferGroupChatLimit = 100
+	// unassignedPageLimit 获取待分配的离职成员列表每页返回的数量上限
+	unassignedPageLimit = 1000
+	// groupChatPageLimit 获取客户群列表每页返回的数量上限
+	groupChatPageLimit = 1000
+	// defaultTransferPollInterval 默认查询客户接替状态的间隔
+	defaultTransferPollInterval = time.Minute
+	// groupChatStatusUnassigned 客户群跟进状态：离职待继承
+	groupChatStatusUnassigned = 1
+)
+
+// 客户接替状态
+const (
+	// TransferStatusAccepted 接替完毕
+	TransferStatusAccepted = 1
+	// TransferStatusWaiting 等待接替，客户24小时内未拒绝时自动接替
+	TransferStatusWaiting = 2
+	// TransferStatusRefused 客户拒绝
+	TransferStatusRefused = 3
+	// TransferStatusLimitExceed 接替成员客户达到上限
+	TransferStatusLimitExceed = 4
+	// TransferStatusNoRecord 无接替记录
+	TransferStatusNoRecord = 5
+)
+
+// HandoverPolicy 成员客户与客户群的交接策略
+type HandoverPolicy struct {
+	Resigned       bool   // 成员是否已离职，离职成员使用离职继承接口，否则使用在职继承接口
+	TakeoverUserID string // 接替成员的userid，必填
+	// Assign 为客户指定接替成员，返回空字符串时使用TakeoverUserID
+	Assign             func(externalUserID string) string
+	GroupChatOwner     string        // 客户群的新群主，为空时使用TakeoverUserID
+	SkipGroupChats     bool          // 是否不交接客户群
+	TransferSuccessMsg string        // 在职继承时发送给客户的消息，为空时使用企业微信的默认消息
+	PollInterval       time.Duration // 查询客户接替状态的间隔，默认1分钟
+	PollTimeout        time.Duration // 等待客户接替的最长时间，为0时只查询一次，仍在等待的客户可稍后调用Poll查询
+}
+
+// CustomerHandover 单个客户的交接结果
+type CustomerHandover struct {
+	ExternalUserID string `json:"external_userid"`
+	TakeoverUserID string `json:"takeover_userid"`
+	ErrCode        int    `json:"errcode"`       // 分配客户的错误码，不为0时分配失败
+	Status         int    `json:"status"`        // 接替状态，见 TransferStatusAccepted 等常量，分配失败时为0
+	TakeoverTime   int64  `json:"takeover_time"` // 接替客户的时间，仅接替完毕时有值
+}
+
+// Failed 客户是否分配失败或接替失败
+func (c CustomerHandover) Failed() bool {
+	return c.ErrCode != 0 || c.Status == TransferStatusRefused || c.Status == TransferStatusLimitExceed || c.Status == TransferStatusNoRecord
+}
+
+// GroupChatHandover 单个客户群的交接结果
+type GroupChatHandover struct {
+	ChatID   string `json:"chat_id"`
+	NewOwner string `json:"new_owner"`
+	ErrCode  int    `json:"errcode"` // 不为0时继承失败
+	ErrMsg   string `json:"errmsg"`
+}
+
+// OffboardReport 成员交接报告
+type OffboardReport struct {
+	HandoverUserID string              `json:"handover_userid"`
+	Resigned       bool                `json:"resigned"`
+	Customers      []CustomerHandover  `json:"customers"`
+	GroupChats     []GroupChatHandover `json:"group_chats"`
+}
+
+// Accepted 返回已接替完毕的客户
+func (r *OffboardReport) Accepted() []CustomerHandover {
+	return r.customers(func(c CustomerHandover) bool { return c.ErrCode == 0 && c.Status == TransferStatusAccepted })
+}
+
+// Waiting 返回仍在24小时接替等待期内的客户
+func (r *OffboardReport) Waiting() []CustomerHandover {
+	return r.customers(func(c CustomerHandover) bool { return c.ErrCode == 0 && c.Status == TransferStatusWaiting })
+}
+
+// Failed 返回分配失败或接替失败的客户
+func (r *OffboardReport) Failed() []CustomerHandover {
+	return r.customers(CustomerHandover.Failed)
+}
+
+// FailedGroupChats 返回继承失败的客户群
+func (r *OffboardReport) FailedGroupChats() []GroupChatHandover {
+	var failed []GroupChatHandover
+	for _, chat := range r.GroupChats {
+		if chat.ErrCode != 0 {
+			failed = append(failed, chat)
+		}
+	}
+	return failed
+}
+
+// customers 返回满足条件的客户
+func (r *OffboardReport) customers(match func(c CustomerHandover) bool) []CustomerHandover {
+	var customers []CustomerHandover
+	for _, customer := range r.Customers {
+		if match(customer) {
+			customers = append(customers, customer)
+		}
+	}
+	return customers
+}
+
+// Offboarder 成员交接，将在职或离职成员的全部客户与客户群分配给接替成员，并跟踪客户接替状态
+type Offboarder struct {
+	client *Client
+	page   *PageOptions
+}
+
+// NewOffboarder 创建成员交接，page为分页拉取的配置，可为nil
+func NewOffboarder(client *Client, page *PageOptions) *Offboarder {
+	return &Offboarder{client: client, page: page}
+}
+
+// Offboard 按交接策略分配成员的全部客户与客户群，并查询客户接替状态直到全部接替完成或超过PollTimeout
+// 出错时返回已完成部分的报告
+func (o *Offboarder) Offboard(ctx stdcontext.Context, userID string, policy HandoverPolicy) (*OffboardReport, error) {
+	if policy.TakeoverUserID == "" {
+		return nil, fmt.Errorf("takeover userid is required")
+	}
+	report := &OffboardReport{HandoverUserID: userID, Resigned: policy.Resigned}
+	customers, err := o.customers(ctx, userID, policy.Resigned)
+	if err != nil {
+		return report, err
+	}
+	if err = o.transferCustomers(ctx, report, customers, policy); err != nil {
+		return report, err
+	}
+	if !policy.SkipGroupChats {
+		if err = o.transferGroupChats(ctx, report, policy); err != nil {
+			return report, err
+		}
+	}
+	return report, o.wait(ctx, report, policy)
+}
+
+// customers 获取待交接的客户，离职成员从待分配列表获取
+func (o *Offboarder) customers(ctx stdcontext.Context, userID string, resigned bool) ([]string, error) {
+	if !resigned {
+		customers, err := o.client.GetExternalUserList(userID)
+		if err != nil {
+			return nil, fmt.Errorf("get external user list: %w", err)
+		}
+		return customers, nil
+	}
+	var customers []string
+	seen := make(map[string]bool)
+	req := &GetUnassignedListRequest{PageSize: o.page.limitedPageSize(unassignedPageLimit)}
+	err := paginate(ctx, o.page, "", func(cursor string) (string, error) {
+		req.Cursor = cursor
+		result, err := o.client.GetUnassignedList(req)
+		if err != nil {
+			return "", err
+		}
+		for _, info := range result.Info {
+			if info.HandoverUserID == userID && !seen[info.ExternalUserID] {
+				seen[info.ExternalUserID] = true
+				customers = append(customers, info.ExternalUserID)
+			}
+		}
+		if result.IsLast {
+			return "", nil
+		}
+		return result.NextCursor, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("get unassigned list: %w", err)
+	}
+	return customers, nil
+}
+
+// transferCustomers 按接替成员分组，每次最多分配100个客户
+func (o *Offboarder) transferCustomers(ctx stdcontext.Context, report *OffboardReport, customers []string, policy HandoverPolicy) error {
+	var takeovers []string
+	groups := make(map[string][]string)
+	for _, externalUserID := range customers {
+		takeover := policy.TakeoverUserID
+		if policy.Assign != nil {
+			if assigned := policy.Assign(externalUserID); assigned != "" {
+				takeover = assigned
+			}
+		}
+		if _, ok := groups[takeover]; !ok {
+			takeovers = append(takeovers, takeover)
+		}
+		groups[takeover] = append(groups[takeover], externalUserID)
+	}
+
+	for _, takeover := range takeovers {
+		list := groups[takeover]
+		for start := 0; start < len(list); start += transferCustomerLimit {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			end := start + transferCustomerLimit
+			if end > len(list) {
+				end = len(list)
+			}
+			items, err := o.transfer(report, takeover, list[start:end], policy.TransferSuccessMsg)
+			if err != nil {
+				return fmt.Errorf("transfer customers to %s: %w", takeover, err)
+			}
+			codes := make(map[string]int, len(items))
+			for _, item := range items {
+				codes[item.ExternalUserID] = item.ErrCode
+			}
+			for _, externalUserID := range list[start:end] {
+				customer := CustomerHandover{ExternalUserID: externalUserID, TakeoverUserID: takeover, ErrCode: codes[externalUserID]}
+				if customer.ErrCode == 0 {
+					customer.Status = TransferStatusWaiting
+				}
+				report.Customers = append(report.Customers, customer)
+			}
+		}
+	}
+	return nil
+}
+
+// transfer 调用在职或离职继承接口分配一批客户
+func (o *Offboarder) transfer(report *OffboardReport, takeover string, customers []string, message string) ([]TransferCustomerItem, error) {
+	if report.Resigned {
+		result, err := o.client.ResignedTransferCustomer(&ResignedTransferCustomerRequest{
+			HandoverUserID: report.HandoverUserID,
+			TakeoverUserID: takeover,
+			ExternalUserID: customers,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return result.Customer, nil
+	}
+	result, err := o.client.TransferCustomer(&TransferCustomerRequest{
+		HandoverUserID:     report.HandoverUserID,
+		TakeoverUserID:     takeover,
+		ExternalUserID:     customers,
+		TransferSuccessMsg: message,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result.Customer, nil
+}
+
+// transferGroupChats 将成员作为群主的客户群分配给新群主，每次最多分配100个群
+func (o *Offboarder) transferGroupChats(ctx stdcontext.Context, report *OffboardReport, policy HandoverPolicy) error {
+	owner := policy.GroupChatOwner
+	if owner == "" {
+		owner = policy.TakeoverUserID
+	}
+	var chats []string
+	req := &GroupChatListRequest{OwnerFilter: OwnerFilter{UseridList: []string{report.HandoverUserID}}, Limit: o.page.limitedPageSize(groupChatPageLimit)}
+	if report.Resigned {
+		req.StatusFilter = groupChatStatusUnassigned
+	}
+	err := paginate(ctx, o.page, "", func(cursor string) (string, error) {
+		req.Cursor = cursor
+		result, err := o.client.GetGroupChatList(req)
+		if err != nil {
+			return "", err
+		}
+		for _, chat := range result.GroupChatList {
+			chats = append(chats, chat.ChatID)
+		}
+		return result.NextCursor, nil
+	})
+	if err != nil {
+		return fmt.Errorf("get group chat list: %w", err)
+	}
+
+	for start := 0; start < len(chats); start += transferGroupChatLimit {
+		end := start + transferGroupChatLimit
+		if end > len(chats) {
+			end = len(chats)
+		}
+		failed, err := o.transferChats(report.Resigned, chats[start:end], owner)
+		if err != nil {
+			return fmt.Errorf("transfer group chats to %s: %w", owner, err)
+		}
+		failures := make(map[string]FailedChat, len(failed))
+		for _, chat := range failed {
+			failures[chat.ChatID] = chat
+		}
+		for _, chatID := range chats[start:end] {
+			report.GroupChats = append(report.GroupChats, GroupChatHandover{
+				ChatID:   chatID,
+				NewOwner: owner,
+				ErrCode:  failures[chatID].ErrCode,
+				ErrMsg:   failures[chatID].ErrMsg,
+			})
+		}
+	}
+	return nil
+}
+
+// transferChats 调用在职或离职继承接口分配一批客户群，返回继承失败的群
+func (o *Offboarder) transferChats(resigned bool, chats []string, owner string) ([]FailedChat, error) {
+	if resigned {
+		result, err := o.client.GroupChatTransfer(&GroupChatTransferRequest{ChatIDList: chats, NewOwner: owner})
+		if err != nil {
+			return nil, err
+		}
+		return result.FailedChatList, nil
+	}
+	result, err := o.client.GroupChatOnJobTransfer(&GroupChatOnJobTransferRequest{ChatIDList: chats, NewOwner: owner})
+	if err != nil {
+		return nil, err
+	}
+	return result.FailedChatList, nil
+}
+
+// wait 查询客户接替状态，直到没有等待接替的客户或超过PollTimeout
+func (o *Offboarder) wait(ctx stdcontext.Context, report *OffboardReport, policy HandoverPolicy) error {
+	interval := policy.PollInterval
+	if interval <= 0 {
+		interval = defaultTransferPollInterval
+	}
+	deadline := time.Now().Add(policy.PollTimeout)
+	for {
+		if err := o.Poll(ctx, report); err != nil {
+			return err
+		}
+		if len(report.Waiting()) == 0 || !time.Now().Add(interval).Before(deadline) {
+			return nil
+		}
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// Poll 查询报告中等待接替的客户的最新接替状态
+func (o *Offboarder) Poll(ctx stdcontext.Context, report *OffboardReport) error {
+	waiting := make(map[string]map[string]int)
+	for i, customer := range report.Customers {
+		if customer.ErrCode != 0 || customer.Status != TransferStatusWaiting {
+			continue
+		}
+		if waiting[customer.TakeoverUserID] == nil {
+			waiting[customer.TakeoverUserID] = make(map[string]int)
+		}
+		waiting[customer.TakeoverUserID][customer.ExternalUserID] = i
+	}
+	for takeover, customers := range waiting {
+		err := paginate(ctx, o.page, "", func(cursor string) (string, error) {
+			items, next, err := o.result(report, takeover, cursor)
+			if err != nil {
+				return "", err
+			}
+			for _, item := range items {
+				if i, ok := customers[item.ExternalUserID]; ok {
+					report.Customers[i].Status = item.Status
+					report.Customers[i].TakeoverTime = item.TakeoverTime
+				}
+			}
+			return next, nil
+		})
+		if err != nil {
+			return fmt.Errorf("get transfer result of %s: %w", takeover, err)
+		}
+	}
+	return nil
+}
+
+// result 调用在职或离职继承接口查询一页客户接替状态
+func (o *Offboarder) result(report *OffboardReport, takeover, cursor string) ([]TransferResultItem, string, error) {
+	if report.Resigned {
+		result, err := o.client.ResignedTransferResult(&ResignedTransferResultRequest{
+			HandoverUserID: report.HandoverUserID,
+			TakeoverUserID: takeover,
+			Cursor:         cursor,
+		})
+		if err != nil {
+			return nil, "", err
+		}
+		return result.Customer, result.NextCursor, nil
+	}
+	result, err := o.client.TransferResult(&TransferResultRequest{
+		HandoverUserID: report.HandoverUserID,
+		TakeoverUserID: takeover,
+		Cursor:         cursor,
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	return result.Customer, result.NextCursor, nil
+}
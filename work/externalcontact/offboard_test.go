@@ -0,0 +1,100 @@
+package externalcontact
+
+import (
+	stdcontext "context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/h2non/gock.v1"
+)
+
+// transferredCustomers 生成分配客户接口返回的客户列表，failed中的客户分配失败
+func transferredCustomers(start, end int, failed map[string]int) map[string]interface{} {
+	customers := make([]map[string]interface{}, 0, end-start)
+	for i := start; i < end; i++ {
+		externalUserID := fmt.Sprintf("wm%d", i)
+		customers = append(customers, map[string]interface{}{"external_userid": externalUserID, "errcode": failed[externalUserID]})
+	}
+	return map[string]interface{}{"customer": customers}
+}
+
+func TestOffboarderResigned(t *testing.T) {
+	defer gock.Off()
+	info := []map[string]interface{}{{"handover_userid": "other", "external_userid": "wm_other"}}
+	for i := 0; i < 150; i++ {
+		info = append(info, map[string]interface{}{"handover_userid": "zhangsan", "external_userid": fmt.Sprintf("wm%d", i)})
+	}
+	mockExternalContactAPI("get_unassigned_list", `"cursor":"","page_size":1000`, map[string]interface{}{"info": info, "is_last": true})
+	// 每次最多分配100个客户，wm149指定由wangwu接替
+	mockExternalContactAPI("resigned/transfer_customer", `"handover_userid":"zhangsan","takeover_userid":"lisi","external_userid":\["wm0",`,
+		transferredCustomers(0, 100, map[string]int{"wm0": 40096}))
+	mockExternalContactAPI("resigned/transfer_customer", `"takeover_userid":"lisi","external_userid":\["wm100",.*"wm148"\]`,
+		transferredCustomers(100, 149, nil))
+	mockExternalContactAPI("resigned/transfer_customer", `"takeover_userid":"wangwu","external_userid":\["wm149"\]`,
+		transferredCustomers(149, 150, nil))
+	mockExternalContactAPI("groupchat/list", `"status_filter":1,"owner_filter":\{"userid_list":\["zhangsan"\]\},"cursor":"","limit":1000`,
+		map[string]interface{}{"group_chat_list": []map[string]interface{}{{"chat_id": "chat1", "status": 1}, {"chat_id": "chat2", "status": 1}}})
+	mockExternalContactAPI("groupchat/transfer", `"chat_id_list":\["chat1","chat2"\],"new_owner":"lisi"`,
+		map[string]interface{}{"failed_chat_list": []map[string]interface{}{{"chat_id": "chat2", "errcode": 90500, "errmsg": "owner not resigned"}}})
+	mockExternalContactAPI("resigned/transfer_result", `"handover_userid":"zhangsan","takeover_userid":"lisi","cursor":""`,
+		map[string]interface{}{"customer": []map[string]interface{}{
+			{"external_userid": "wm1", "status": TransferStatusAccepted, "takeover_time": 1700000000},
+			{"external_userid": "wm2", "status": TransferStatusRefused},
+		}})
+	mockExternalContactAPI("resigned/transfer_result", `"takeover_userid":"wangwu"`, map[string]interface{}{})
+
+	offboarder := NewOffboarder(newTestClient(), &PageOptions{Interval: -1})
+	report, err := offboarder.Offboard(stdcontext.Background(), "zhangsan", HandoverPolicy{
+		Resigned:       true,
+		TakeoverUserID: "lisi",
+		Assign: func(externalUserID string) string {
+			if externalUserID == "wm149" {
+				return "wangwu"
+			}
+			return ""
+		},
+	})
+	assert.Nil(t, err)
+	assert.True(t, gock.IsDone())
+
+	assert.Len(t, report.Customers, 150)
+	assert.Equal(t, []CustomerHandover{{ExternalUserID: "wm1", TakeoverUserID: "lisi", Status: TransferStatusAccepted, TakeoverTime: 1700000000}}, report.Accepted())
+	assert.Len(t, report.Failed(), 2)
+	assert.Len(t, report.Waiting(), 147)
+	assert.Len(t, report.GroupChats, 2)
+	assert.Equal(t, []GroupChatHandover{{ChatID: "chat2", NewOwner: "lisi", ErrCode: 90500, ErrMsg: "owner not resigned"}}, report.FailedGroupChats())
+}
+
+func TestOffboarderOnJob(t *testing.T) {
+	defer gock.Off()
+	client := newTestClient()
+	gock.New("https://qyapi.weixin.qq.com").Get("/cgi-bin/externalcontact/list").MatchParam("userid", "wangwu").
+		Reply(200).JSON(map[string]interface{}{"errcode": 0, "external_userid": []string{"wm1", "wm2"}})
+	mockExternalContactAPI("transfer_customer", `"handover_userid":"wangwu","takeover_userid":"lisi","external_userid":\["wm1","wm2"\],"transfer_success_msg":"您好，后续由我为您服务"`,
+		transferredCustomers(1, 3, nil))
+	// 在职成员的客户群不按跟进状态过滤，分配给指定的新群主
+	mockExternalContactAPI("groupchat/list", `"status_filter":0,"owner_filter":\{"userid_list":\["wangwu"\]\}`,
+		map[string]interface{}{"group_chat_list": []map[string]interface{}{{"chat_id": "chat3"}}})
+	mockExternalContactAPI("groupchat/onjob_transfer", `"chat_id_list":\["chat3"\],"new_owner":"zhaoliu"`, map[string]interface{}{})
+	mockExternalContactAPI("transfer_result", `"handover_userid":"wangwu","takeover_userid":"lisi","cursor":""`, map[string]interface{}{
+		"next_cursor": "next",
+		"customer":    []map[string]interface{}{{"external_userid": "wm1", "status": TransferStatusAccepted}},
+	})
+	mockExternalContactAPI("transfer_result", `"cursor":"next"`, map[string]interface{}{
+		"customer": []map[string]interface{}{{"external_userid": "wm2", "status": TransferStatusWaiting}},
+	})
+
+	report, err := NewOffboarder(client, &PageOptions{Interval: -1}).Offboard(stdcontext.Background(), "wangwu", HandoverPolicy{
+		TakeoverUserID:     "lisi",
+		GroupChatOwner:     "zhaoliu",
+		TransferSuccessMsg: "您好，后续由我为您服务",
+	})
+	assert.Nil(t, err)
+	assert.True(t, gock.IsDone())
+	assert.False(t, report.Resigned)
+	assert.Len(t, report.Accepted(), 1)
+	assert.Equal(t, []CustomerHandover{{ExternalUserID: "wm2", TakeoverUserID: "lisi", Status: TransferStatusWaiting}}, report.Waiting())
+	assert.Equal(t, []GroupChatHandover{{ChatID: "chat3", NewOwner: "zhaoliu"}}, report.GroupChats)
+	assert.Empty(t, report.FailedGroupChats())
+}
@@ -0,0 +1,471 @@
+package externalcontact
+
+import (
+	stdcontext "context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// CustomerRecord 本地快照中的客户，包含客户信息与全部跟进成员
+type CustomerRecord struct {
+	ExternalContact ExternalUser `json:"external_contact"`
+	FollowUser      []FollowUser `json:"follow_user"`
+	UpdatedAt       time.Time    `json:"updated_at"`
+}
+
+// Follow 返回指定成员与客户的跟进关系，成员未添加该客户时返回nil
+func (r *CustomerRecord) Follow(userID string) *FollowUser {
+	for i := range r.FollowUser {
+		if r.FollowUser[i].UserID == userID {
+			return &r.FollowUser[i]
+		}
+	}
+	return nil
+}
+
+// HasTag 客户是否在任一跟进成员下有指定标签
+func (r *CustomerRecord) HasTag(tagID string) bool {
+	for _, follow := range r.FollowUser {
+		for _, tag := range follow.Tags {
+			if tag.TagID == tagID {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// CustomerStore 客户快照存储
+type CustomerStore interface {
+	// GetCustomer 获取客户，不存在时返回nil
+	GetCustomer(externalUserID string) (*CustomerRecord, error)
+	// SaveCustomer 保存客户
+	SaveCustomer(record CustomerRecord) error
+	// DeleteCustomer 删除客户
+	DeleteCustomer(externalUserID string) error
+	// RangeCustomers 遍历全部客户，fn返回false时停止遍历
+	RangeCustomers(fn func(record CustomerRecord) bool) error
+}
+
+// MemoryCustomerStore 基于内存的客户快照存储，仅适用于单实例部署
+type MemoryCustomerStore struct {
+	mu        sync.RWMutex
+	customers map[string]CustomerRecord
+}
+
+// NewMemoryCustomerStore 创建基于内存的客户快照存储
+func NewMemoryCustomerStore() *MemoryCustomerStore {
+	return &MemoryCustomerStore{customers: make(map[string]CustomerRecord)}
+}
+
+// GetCustomer 获取客户，不存在时返回nil
+func (s *MemoryCustomerStore) GetCustomer(externalUserID string) (*CustomerRecord, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	record, ok := s.customers[externalUserID]
+	if !ok {
+		return nil, nil
+	}
+	return &record, nil
+}
+
+// SaveCustomer 保存客户
+func (s *MemoryCustomerStore) SaveCustomer(record CustomerRecord) error {
+	s.mu.Lock()
+	s.customers[record.ExternalContact.ExternalUserID] = record
+	s.mu.Unlock()
+	return nil
+}
+
+// DeleteCustomer 删除客户
+func (s *MemoryCustomerStore) DeleteCustomer(externalUserID string) error {
+	s.mu.Lock()
+	delete(s.customers, externalUserID)
+	s.mu.Unlock()
+	return nil
+}
+
+// RangeCustomers 遍历全部客户
+func (s *MemoryCustomerStore) RangeCustomers(fn func(record CustomerRecord) bool) error {
+	s.mu.RLock()
+	records := make([]CustomerRecord, 0, len(s.customers))
+	for _, record := range s.customers {
+		records = append(records, record)
+	}
+	s.mu.RUnlock()
+	for _, record := range records {
+		if !fn(record) {
+			break
+		}
+	}
+	return nil
+}
+
+// SnapshotOptions 客户快照配置
+type SnapshotOptions struct {
+	Store CustomerStore // 客户存储，默认使用内存存储
+	Page  *PageOptions  // 全量拉取时的分页配置，可为nil
+}
+
+// Snapshot 企业客户的本地快照，全量拉取客户、跟进成员与标签后，根据客户联系回调事件增量更新
+type Snapshot struct {
+	client *Client
+	store  CustomerStore
+	page   *PageOptions
+
+	mu   sync.Mutex     // 保护客户记录的读取与保存
+	tags map[string]Tag // 企业标签库，用于补全批量接口只返回的标签ID
+}
+
+// NewSnapshot 创建企业客户的本地快照
+func NewSnapshot(client *Client, opts *SnapshotOptions) *Snapshot {
+	var options SnapshotOptions
+	if opts != nil {
+		options = *opts
+	}
+	if options.Store == nil {
+		options.Store = NewMemoryCustomerStore()
+	}
+	return &Snapshot{client: client, store: options.Store, page: options.Page, tags: make(map[string]Tag)}
+}
+
+// Bootstrap 全量拉取配置了客户联系功能的成员的全部客户，覆盖本地快照，并删除快照中已不存在的客户
+// 拉取期间由回调事件更新的客户以回调事件的结果为准
+func (s *Snapshot) Bootstrap(ctx stdcontext.Context) error {
+	start := time.Now()
+	if err := s.refreshTags(); err != nil {
+		return err
+	}
+	userIDs, err := s.client.GetFollowUserList()
+	if err != nil {
+		return fmt.Errorf("get follow user list: %w", err)
+	}
+
+	tags := s.tagDictionary()
+	records := make(map[string]*CustomerRecord)
+	for begin := 0; begin < len(userIDs); begin += batchGetUserIDLimit {
+		end := begin + batchGetUserIDLimit
+		if end > len(userIDs) {
+			end = len(userIDs)
+		}
+		req := BatchGetExternalUserDetailsRequest{UserIDList: userIDs[begin:end], Limit: s.page.limitedPageSize(batchGetPageLimit)}
+		err = paginate(ctx, s.page, "", func(cursor string) (string, error) {
+			req.Cursor = cursor
+			result, err := s.client.BatchGetExternalUserDetails(req)
+			if err != nil {
+				return "", err
+			}
+			for _, item := range result.ExternalContactList {
+				mergeFollowInfo(records, item, tags)
+			}
+			return result.NextCursor, nil
+		})
+		if err != nil {
+			return fmt.Errorf("batch get external user details: %w", err)
+		}
+	}
+	return s.replace(records, start)
+}
+
+// mergeFollowInfo 合并批量接口返回的一条跟进关系，按企业标签库补全标签名称
+func mergeFollowInfo(records map[string]*CustomerRecord, item ExternalUserForBatch, tags map[string]Tag) {
+	externalUserID := item.ExternalContact.ExternalUserID
+	record, ok := records[externalUserID]
+	if !ok {
+		record = &CustomerRecord{ExternalContact: externalUser(item.ExternalContact)}
+		records[externalUserID] = record
+	}
+	info := item.FollowInfo
+	follow := FollowUser{
+		UserID:         info.UserID,
+		Remark:         info.Remark,
+		Description:    info.Description,
+		CreateTime:     info.CreateTime,
+		RemarkCorpName: info.RemarkCorpName,
+		RemarkMobiles:  info.RemarkMobiles,
+		OperUserID:     info.OperUserID,
+		AddWay:         info.AddWay,
+		WeChatChannels: info.WeChatChannels,
+		State:          info.State,
+	}
+	for _, tagID := range info.TagID {
+		tag, ok := tags[tagID]
+		if !ok {
+			tag = Tag{TagID: tagID}
+		}
+		follow.Tags = append(follow.Tags, tag)
+	}
+	record.FollowUser = append(record.FollowUser, follow)
+}
+
+// externalUser 将批量接口返回的客户信息转换为客户详情
+func externalUser(contact ExternalContact) ExternalUser {
+	user := ExternalUser{
+		ExternalUserID: contact.ExternalUserID,
+		Name:           contact.Name,
+		Avatar:         contact.Avatar,
+		Type:           contact.Type,
+		Gender:         contact.Gender,
+		UnionID:        contact.UnionID,
+		Position:       contact.Position,
+		CorpName:       contact.CorpName,
+		CorpFullName:   contact.CorpFullName,
+	}
+	if contact.ExternalProfile != nil {
+		if data, err := json.Marshal(contact.ExternalProfile); err == nil {
+			profile := &ExternalProfile{}
+			if json.Unmarshal(data, profile) == nil {
+				user.ExternalProfile = profile
+			}
+		}
+	}
+	return user
+}
+
+// replace 保存全量拉取的客户，并删除拉取开始前更新、且本次未拉取到的客户；拉取期间已由回调事件更新的客户保持不变
+func (s *Snapshot) replace(records map[string]*CustomerRecord, start time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	for externalUserID, record := range records {
+		current, err := s.store.GetCustomer(externalUserID)
+		if err != nil {
+			return err
+		}
+		if current != nil && current.UpdatedAt.After(start) {
+			continue
+		}
+		record.UpdatedAt = now
+		if err = s.store.SaveCustomer(*record); err != nil {
+			return err
+		}
+	}
+	var stale []string
+	err := s.store.RangeCustomers(func(record CustomerRecord) bool {
+		if _, ok := records[record.ExternalContact.ExternalUserID]; !ok && record.UpdatedAt.Before(start) {
+			stale = append(stale, record.ExternalContact.ExternalUserID)
+		}
+		return true
+	})
+	if err != nil {
+		return err
+	}
+	for _, externalUserID := range stale {
+		if err = s.store.DeleteCustomer(externalUserID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// refreshTags 拉取企业标签库
+func (s *Snapshot) refreshTags() error {
+	groups, err := s.client.GetCropTagList(GetCropTagRequest{})
+	if err != nil {
+		return fmt.Errorf("get corp tag list: %w", err)
+	}
+	tags := make(map[string]Tag)
+	for _, group := range groups {
+		for _, item := range group.Tag {
+			tags[item.ID] = Tag{GroupName: group.GroupName, TagName: item.Name, Type: 1, TagID: item.ID}
+		}
+	}
+	s.mu.Lock()
+	s.tags = tags
+	s.mu.Unlock()
+	return nil
+}
+
+// Handle 根据企业客户事件与企业客户标签事件更新本地快照，其他事件直接返回
+func (s *Snapshot) Handle(msg EventCallbackMessage) error {
+	switch msg.Event {
+	case EventChangeExternalContact:
+		return s.handleContact(msg)
+	case EventChangeExternalTag:
+		return s.handleTag(msg)
+	}
+	return nil
+}
+
+// handleContact 处理企业客户事件
+func (s *Snapshot) handleContact(msg EventCallbackMessage) error {
+	switch msg.ChangeType {
+	case ChangeTypeAddExternalContact, ChangeTypeEditExternalContact, ChangeTypeAddHalfExternalContact:
+		return s.Refresh(msg.ExternalUserID)
+	case ChangeTypeDelExternalContact, ChangeTypeDelFollowUser:
+		return s.removeFollow(msg.ExternalUserID, msg.UserID)
+	}
+	return nil
+}
+
+// Refresh 重新拉取单个客户的详情与全部跟进成员并保存
+func (s *Snapshot) Refresh(externalUserID string) error {
+	record := CustomerRecord{}
+	err := paginate(stdcontext.Background(), &PageOptions{Interval: -1}, "", func(cursor string) (string, error) {
+		result, err := s.client.GetExternalUserDetail(externalUserID, cursor)
+		if err != nil {
+			return "", err
+		}
+		record.ExternalContact = result.ExternalContact
+		record.FollowUser = append(record.FollowUser, result.FollowUser...)
+		return result.NextCursor, nil
+	})
+	if err != nil {
+		return fmt.Errorf("get external user detail %s: %w", externalUserID, err)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	record.UpdatedAt = time.Now()
+	return s.store.SaveCustomer(record)
+}
+
+// removeFollow 删除成员与客户的跟进关系，客户没有跟进成员时删除客户
+func (s *Snapshot) removeFollow(externalUserID, userID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	record, err := s.store.GetCustomer(externalUserID)
+	if err != nil || record == nil {
+		return err
+	}
+	follows := make([]FollowUser, 0, len(record.FollowUser))
+	for _, follow := range record.FollowUser {
+		if follow.UserID != userID {
+			follows = append(follows, follow)
+		}
+	}
+	if len(follows) == 0 {
+		return s.store.DeleteCustomer(externalUserID)
+	}
+	record.FollowUser = follows
+	record.UpdatedAt = time.Now()
+	return s.store.SaveCustomer(*record)
+}
+
+// handleTag 处理企业客户标签事件，标签或标签组删除时从客户移除对应标签，改名时更新客户的标签名称
+// 成员为客户打标签会触发编辑企业客户事件，由handleContact处理
+func (s *Snapshot) handleTag(msg EventCallbackMessage) error {
+	switch msg.ChangeType {
+	case ChangeTypeDelete:
+		if msg.TagType == TagTypeTag {
+			return s.rewriteTags(func(tag Tag) (Tag, bool) { return tag, tag.TagID != msg.ID })
+		}
+		// 标签组删除后无法再查询组内的标签，按最新的企业标签库移除已不存在的企业标签
+		if err := s.refreshTags(); err != nil {
+			return err
+		}
+		tags := s.tagDictionary()
+		return s.rewriteTags(func(tag Tag) (Tag, bool) {
+			_, ok := tags[tag.TagID]
+			return tag, ok || tag.Type != 1
+		})
+	case ChangeTypeUpdate:
+		if msg.StrategyID != 0 {
+			return nil
+		}
+		if err := s.refreshTags(); err != nil {
+			return err
+		}
+		tags := s.tagDictionary()
+		return s.rewriteTags(func(tag Tag) (Tag, bool) {
+			if latest, ok := tags[tag.TagID]; ok {
+				tag.GroupName, tag.TagName = latest.GroupName, latest.TagName
+			}
+			return tag, true
+		})
+	}
+	return nil
+}
+
+// tagDictionary 返回当前的企业标签库
+func (s *Snapshot) tagDictionary() map[string]Tag {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.tags
+}
+
+// rewriteTags 改写全部客户的标签，rewrite返回false时移除该标签，仅保存发生变化的客户
+func (s *Snapshot) rewriteTags(rewrite func(tag Tag) (Tag, bool)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var changed []CustomerRecord
+	err := s.store.RangeCustomers(func(record CustomerRecord) bool {
+		dirty := false
+		follows := make([]FollowUser, len(record.FollowUser))
+		for i, follow := range record.FollowUser {
+			tags := make([]Tag, 0, len(follow.Tags))
+			for _, tag := range follow.Tags {
+				latest, keep := rewrite(tag)
+				if !keep || latest != tag {
+					dirty = true
+				}
+				if keep {
+					tags = append(tags, latest)
+				}
+			}
+			follow.Tags = tags
+			follows[i] = follow
+		}
+		if dirty {
+			record.FollowUser = follows
+			changed = append(changed, record)
+		}
+		return true
+	})
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	for _, record := range changed {
+		record.UpdatedAt = now
+		if err = s.store.SaveCustomer(record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Customer 获取客户，不存在时返回nil
+func (s *Snapshot) Customer(externalUserID string) (*CustomerRecord, error) {
+	return s.store.GetCustomer(externalUserID)
+}
+
+// CustomerByUnionID 按unionid获取客户，不存在时返回nil
+func (s *Snapshot) CustomerByUnionID(unionID string) (*CustomerRecord, error) {
+	var found *CustomerRecord
+	err := s.store.RangeCustomers(func(record CustomerRecord) bool {
+		if unionID != "" && record.ExternalContact.UnionID == unionID {
+			found = &record
+			return false
+		}
+		return true
+	})
+	return found, err
+}
+
+// CustomersByFollowUser 获取成员添加的全部客户，按external_userid排序
+func (s *Snapshot) CustomersByFollowUser(userID string) ([]CustomerRecord, error) {
+	return s.filter(func(record *CustomerRecord) bool { return record.Follow(userID) != nil })
+}
+
+// CustomersByTag 获取在任一跟进成员下有指定标签的全部客户，按external_userid排序
+func (s *Snapshot) CustomersByTag(tagID string) ([]CustomerRecord, error) {
+	return s.filter(func(record *CustomerRecord) bool { return record.HasTag(tagID) })
+}
+
+// filter 获取满足条件的客户
+func (s *Snapshot) filter(match func(record *CustomerRecord) bool) ([]CustomerRecord, error) {
+	var records []CustomerRecord
+	err := s.store.RangeCustomers(func(record CustomerRecord) bool {
+		if match(&record) {
+			records = append(records, record)
+		}
+		return true
+	})
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].ExternalContact.ExternalUserID < records[j].ExternalContact.ExternalUserID
+	})
+	return records, err
+}
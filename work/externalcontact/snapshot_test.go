@@ -0,0 +1,84 @@
+package externalcontact
+
+import (
+	stdcontext "context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/h2non/gock.v1"
+)
+
+func mockCorpTagList(vipName string) {
+	gock.New("https://qyapi.weixin.qq.com").Post("/cgi-bin/externalcontact/get_corp_tag_list").
+		Reply(200).JSON(map[string]interface{}{"errcode": 0, "tag_group": []map[string]interface{}{{
+		"group_id": "level", "group_name": "等级",
+		"tag": []map[string]interface{}{{"id": "vip", "name": vipName}, {"id": "new", "name": "新客"}},
+	}}})
+}
+
+func TestSnapshot(t *testing.T) {
+	defer gock.Off()
+	store := NewMemoryCustomerStore()
+	assert.Nil(t, store.SaveCustomer(CustomerRecord{ExternalContact: ExternalUser{ExternalUserID: "stale"}}))
+	snapshot := NewSnapshot(newTestClient(), &SnapshotOptions{Store: store, Page: &PageOptions{Interval: -1}})
+
+	// 批量接口只返回标签ID，按企业标签库补全标签名称；快照中已不存在的客户被删除
+	mockCorpTagList("VIP")
+	gock.New("https://qyapi.weixin.qq.com").Get("/cgi-bin/externalcontact/get_follow_user_list").
+		Reply(200).JSON(map[string]interface{}{"errcode": 0, "follow_user": []string{"zhangsan", "lisi"}})
+	gock.New("https://qyapi.weixin.qq.com").Post("/cgi-bin/externalcontact/batch/get_by_user").
+		BodyString(`"userid_list":\["zhangsan","lisi"\],"cursor":"","limit":100`).
+		Reply(200).JSON(map[string]interface{}{"errcode": 0, "external_contact_list": []map[string]interface{}{
+		{"external_contact": map[string]interface{}{"external_userid": "wm1", "unionid": "union1"}, "follow_info": map[string]interface{}{"userid": "zhangsan", "tag_id": []string{"vip"}}},
+		{"external_contact": map[string]interface{}{"external_userid": "wm1", "unionid": "union1"}, "follow_info": map[string]interface{}{"userid": "lisi"}},
+		{"external_contact": map[string]interface{}{"external_userid": "wm2"}, "follow_info": map[string]interface{}{"userid": "lisi", "tag_id": []string{"new"}}},
+	}})
+	assert.Nil(t, snapshot.Bootstrap(stdcontext.Background()))
+	assert.True(t, gock.IsDone())
+
+	stale, err := snapshot.Customer("stale")
+	assert.Nil(t, err)
+	assert.Nil(t, stale)
+	customer, err := snapshot.CustomerByUnionID("union1")
+	assert.Nil(t, err)
+	assert.Equal(t, "wm1", customer.ExternalContact.ExternalUserID)
+	assert.Len(t, customer.FollowUser, 2)
+	assert.Equal(t, Tag{GroupName: "等级", TagName: "VIP", Type: 1, TagID: "vip"}, customer.Follow("zhangsan").Tags[0])
+	customers, err := snapshot.CustomersByFollowUser("lisi")
+	assert.Nil(t, err)
+	assert.Len(t, customers, 2)
+
+	// 成员为客户打标签后触发编辑企业客户事件，重新拉取客户详情
+	gock.New("https://qyapi.weixin.qq.com").Get("/cgi-bin/externalcontact/get$").MatchParam("external_userid", "wm2").
+		Reply(200).JSON(map[string]interface{}{"errcode": 0,
+		"external_contact": map[string]interface{}{"external_userid": "wm2"},
+		"follow_user": []map[string]interface{}{{"userid": "lisi", "tags": []map[string]interface{}{
+			{"group_name": "等级", "tag_name": "VIP", "type": 1, "tag_id": "vip"},
+		}}},
+	})
+	assert.Nil(t, snapshot.Handle(EventCallbackMessage{Event: EventChangeExternalContact, ChangeType: ChangeTypeEditExternalContact, UserID: "lisi", ExternalUserID: "wm2"}))
+	customers, err = snapshot.CustomersByTag("vip")
+	assert.Nil(t, err)
+	assert.Len(t, customers, 2)
+
+	// 标签改名后重新拉取企业标签库
+	mockCorpTagList("至尊VIP")
+	assert.Nil(t, snapshot.Handle(EventCallbackMessage{Event: EventChangeExternalTag, ChangeType: ChangeTypeUpdate, TagType: TagTypeTag, ID: "vip"}))
+	customer, _ = snapshot.Customer("wm2")
+	assert.Equal(t, "至尊VIP", customer.FollowUser[0].Tags[0].TagName)
+	assert.True(t, gock.IsDone())
+
+	// 标签删除
+	assert.Nil(t, snapshot.Handle(EventCallbackMessage{Event: EventChangeExternalTag, ChangeType: ChangeTypeDelete, TagType: TagTypeTag, ID: "vip"}))
+	customers, err = snapshot.CustomersByTag("vip")
+	assert.Nil(t, err)
+	assert.Empty(t, customers)
+
+	// 删除跟进成员，没有跟进成员的客户从快照中删除
+	assert.Nil(t, snapshot.Handle(EventCallbackMessage{Event: EventChangeExternalContact, ChangeType: ChangeTypeDelExternalContact, UserID: "lisi", ExternalUserID: "wm1"}))
+	assert.Nil(t, snapshot.Handle(EventCallbackMessage{Event: EventChangeExternalContact, ChangeType: ChangeTypeDelFollowUser, UserID: "lisi", ExternalUserID: "wm2"}))
+	customer, _ = snapshot.Customer("wm1")
+	assert.Len(t, customer.FollowUser, 1)
+	customer, _ = snapshot.Customer("wm2")
+	assert.Nil(t, customer)
+}
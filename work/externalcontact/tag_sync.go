@@ -0,0 +1,363 @@
+package externalcontact
+
+import (
+	stdcontext "context"
+	"fmt"
+	"sort"
+)
+
+// customerStrategyPageLimit 获取规则组列表每页返回的数量上限
+const customerStrategyPageLimit = 1000
+
+// TagTaxonomy 企业客户标签库，可序列化为JSON纳入版本管理
+type TagTaxonomy struct {
+	Groups []TagGroupData `json:"groups"`
+}
+
+// TagGroupData 标签组
+type TagGroupData struct {
+	GroupID    string    `json:"group_id,omitempty"`    // 标签组ID，导出时填充，同步时按名称匹配，无需填写
+	Name       string    `json:"name"`                  // 标签组名称
+	Order      int       `json:"order"`                 // 标签组次序值，值大的排序靠前
+	StrategyID int       `json:"strategy_id,omitempty"` // 规则组ID，为0时为企业标签组
+	Tags       []TagData `json:"tags"`
+}
+
+// TagData 标签
+type TagData struct {
+	ID    string `json:"id,omitempty"` // 标签ID，导出时填充，同步时按名称匹配，无需填写
+	Name  string `json:"name"`         // 标签名称
+	Order int    `json:"order"`        // 标签次序值，值大的排序靠前
+}
+
+// ExportTags 导出企业标签与指定规则组的标签，企业标签中属于已导出规则组的标签组不会重复导出
+func (r *Client) ExportTags(strategyIDs ...int) (*TagTaxonomy, error) {
+	taxonomy := &TagTaxonomy{}
+	strategyGroups := make(map[string]bool)
+	for _, strategyID := range strategyIDs {
+		if strategyID == 0 {
+			continue
+		}
+		result, err := r.GetStrategyTagList(&GetStrategyTagListRequest{StrategyID: strategyID})
+		if err != nil {
+			return nil, fmt.Errorf("get strategy %d tag list: %w", strategyID, err)
+		}
+		for _, group := range result.TagGroup {
+			strategyGroups[group.GroupID] = true
+			data := TagGroupData{GroupID: group.GroupID, Name: group.GroupName, Order: group.Order, StrategyID: strategyID}
+			for _, tag := range group.Tag {
+				data.Tags = append(data.Tags, TagData{ID: tag.ID, Name: tag.Name, Order: tag.Order})
+			}
+			taxonomy.Groups = append(taxonomy.Groups, data)
+		}
+	}
+
+	groups, err := r.GetCropTagList(GetCropTagRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("get corp tag list: %w", err)
+	}
+	for _, group := range groups {
+		if group.Deleted || strategyGroups[group.GroupID] {
+			continue
+		}
+		data := TagGroupData{GroupID: group.GroupID, Name: group.GroupName, Order: group.GroupOrder}
+		for _, tag := range group.Tag {
+			if !tag.Deleted {
+				data.Tags = append(data.Tags, TagData{ID: tag.ID, Name: tag.Name, Order: tag.Order})
+			}
+		}
+		taxonomy.Groups = append(taxonomy.Groups, data)
+	}
+	return taxonomy, nil
+}
+
+// TagDiffOptions 标签库差异比较配置
+type TagDiffOptions struct {
+	// Prune 是否删除目标内容中不存在的标签组与标签，仅删除目标内容涉及的企业标签与规则组范围内的标签；
+	// SyncTags会导出全部规则组的标签，规则组的标签组不会被视为企业标签删除；直接调用DiffTags时current应包含全部规则组的标签
+	Prune bool
+}
+
+// TagChange 标签或标签组变更
+type TagChange struct {
+	StrategyID int    // 规则组ID，为0时为企业标签
+	GroupID    string // 标签组ID，标签组需要新建时为空
+	GroupName  string // 标签组名称
+	TagID      string // 标签ID，标签组变更与新建标签时为空
+	Name       string // 标签名称，标签组变更时为空
+	Order      int    // 次序值
+}
+
+// TagPlan 将标签库同步到目标内容需要执行的变更
+type TagPlan struct {
+	AddGroups  []TagGroupData // 需要新建的标签组及其标签
+	EditGroups []TagChange    // 需要修改次序的标签组
+	AddTags    []TagChange    // 需要在已有标签组中新建的标签
+	EditTags   []TagChange    // 需要修改次序的标签
+	DelTags    []TagChange    // 需要删除的标签，仅Prune时生成
+	DelGroups  []TagChange    // 需要删除的标签组，仅Prune时生成
+}
+
+// Empty 标签库是否已与目标内容一致
+func (p *TagPlan) Empty() bool {
+	return len(p.AddGroups)+len(p.EditGroups)+len(p.AddTags)+len(p.EditTags)+len(p.DelTags)+len(p.DelGroups) == 0
+}
+
+// tagGroupKey 标签组在规则组范围内按名称唯一
+type tagGroupKey struct {
+	strategyID int
+	name       string
+}
+
+// DiffTags 比较当前标签库与目标内容，生成同步计划
+// 标签组按规则组与名称匹配，标签按所属标签组与名称匹配；名称变化视为删除后新建
+func DiffTags(current, desired *TagTaxonomy, opts *TagDiffOptions) (*TagPlan, error) {
+	if err := desired.validate(); err != nil {
+		return nil, err
+	}
+	currentGroups := make(map[tagGroupKey]*TagGroupData, len(current.Groups))
+	for i := range current.Groups {
+		group := &current.Groups[i]
+		currentGroups[tagGroupKey{group.StrategyID, group.Name}] = group
+	}
+
+	plan := &TagPlan{}
+	scopes := make(map[int]bool)
+	keptGroups := make(map[tagGroupKey]bool)
+	keptTags := make(map[string]bool)
+	for _, want := range desired.Groups {
+		key := tagGroupKey{want.StrategyID, want.Name}
+		scopes[want.StrategyID] = true
+		keptGroups[key] = true
+		group, ok := currentGroups[key]
+		if !ok {
+			plan.AddGroups = append(plan.AddGroups, want)
+			continue
+		}
+		if group.Order != want.Order {
+			plan.EditGroups = append(plan.EditGroups, TagChange{StrategyID: group.StrategyID, GroupID: group.GroupID, GroupName: group.Name, Order: want.Order})
+		}
+		tags := make(map[string]TagData, len(group.Tags))
+		for _, tag := range group.Tags {
+			tags[tag.Name] = tag
+		}
+		for _, tag := range want.Tags {
+			change := TagChange{StrategyID: group.StrategyID, GroupID: group.GroupID, GroupName: group.Name, Name: tag.Name, Order: tag.Order}
+			existing, ok := tags[tag.Name]
+			switch {
+			case !ok:
+				plan.AddTags = append(plan.AddTags, change)
+			case existing.Order != tag.Order:
+				change.TagID = existing.ID
+				plan.EditTags = append(plan.EditTags, change)
+			}
+			if ok {
+				keptTags[existing.ID] = true
+			}
+		}
+	}
+
+	if opts != nil && opts.Prune {
+		plan.prune(current, scopes, keptGroups, keptTags)
+	}
+	return plan, nil
+}
+
+// prune 删除目标内容涉及的范围内未保留的标签组与标签
+func (p *TagPlan) prune(current *TagTaxonomy, scopes map[int]bool, keptGroups map[tagGroupKey]bool, keptTags map[string]bool) {
+	for _, group := range current.Groups {
+		if !scopes[group.StrategyID] {
+			continue
+		}
+		if !keptGroups[tagGroupKey{group.StrategyID, group.Name}] {
+			p.DelGroups = append(p.DelGroups, TagChange{StrategyID: group.StrategyID, GroupID: group.GroupID, GroupName: group.Name})
+			continue
+		}
+		for _, tag := range group.Tags {
+			if !keptTags[tag.ID] {
+				p.DelTags = append(p.DelTags, TagChange{StrategyID: group.StrategyID, GroupID: group.GroupID, GroupName: group.Name, TagID: tag.ID, Name: tag.Name})
+			}
+		}
+	}
+}
+
+// validate 校验目标内容，标签组名称在规则组范围内唯一、标签名称在标签组内唯一，且每个标签组至少有一个标签
+func (t *TagTaxonomy) validate() error {
+	groups := make(map[tagGroupKey]bool, len(t.Groups))
+	for _, group := range t.Groups {
+		key := tagGroupKey{group.StrategyID, group.Name}
+		if group.Name == "" {
+			return fmt.Errorf("tag group name is required")
+		}
+		if groups[key] {
+			return fmt.Errorf("duplicate tag group %q", group.Name)
+		}
+		groups[key] = true
+		if len(group.Tags) == 0 {
+			return fmt.Errorf("tag group %q requires at least one tag", group.Name)
+		}
+		tags := make(map[string]bool, len(group.Tags))
+		for _, tag := range group.Tags {
+			if tag.Name == "" {
+				return fmt.Errorf("tag name in group %q is required", group.Name)
+			}
+			if tags[tag.Name] {
+				return fmt.Errorf("duplicate tag %q in group %q", tag.Name, group.Name)
+			}
+			tags[tag.Name] = true
+		}
+	}
+	return nil
+}
+
+// SyncTags 导出目标内容涉及的企业标签与规则组标签，与目标内容比较后执行同步，返回执行的同步计划；
+// 内容一致时不会调用任何修改接口，重复执行结果相同
+func (r *Client) SyncTags(desired *TagTaxonomy, opts *TagDiffOptions) (*TagPlan, error) {
+	strategyIDs, err := r.syncStrategyIDs(desired, opts != nil && opts.Prune)
+	if err != nil {
+		return nil, err
+	}
+	current, err := r.ExportTags(strategyIDs...)
+	if err != nil {
+		return nil, err
+	}
+	plan, err := DiffTags(current, desired, opts)
+	if err != nil {
+		return nil, err
+	}
+	return plan, r.ApplyTagPlan(plan)
+}
+
+// syncStrategyIDs 返回同步时需要导出的规则组；
+// 企业标签列表中包含规则组的标签组，删除企业标签时导出全部规则组，以免未声明的规则组标签组被视为企业标签删除
+func (r *Client) syncStrategyIDs(desired *TagTaxonomy, prune bool) ([]int, error) {
+	scopes := make(map[int]bool)
+	var strategyIDs []int
+	add := func(strategyID int) {
+		if !scopes[strategyID] {
+			scopes[strategyID] = true
+			strategyIDs = append(strategyIDs, strategyID)
+		}
+	}
+	corp := false
+	for _, group := range desired.Groups {
+		if group.StrategyID == 0 {
+			corp = true
+			continue
+		}
+		add(group.StrategyID)
+	}
+	if prune && corp {
+		req := &ListCustomerStrategyRequest{Limit: customerStrategyPageLimit}
+		err := r.RangeCustomerStrategy(stdcontext.Background(), req, nil, func(list []StrategyID) error {
+			for _, item := range list {
+				add(item.StrategyID)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("list customer strategy: %w", err)
+		}
+	}
+	sort.Ints(strategyIDs)
+	return strategyIDs, nil
+}
+
+// ApplyTagPlan 按新建标签组、修改标签组、新建标签、修改标签、删除标签、删除标签组的顺序执行同步计划
+func (r *Client) ApplyTagPlan(plan *TagPlan) error {
+	for _, group := range plan.AddGroups {
+		if err := r.addTags(group.StrategyID, "", group.Name, group.Order, group.Tags); err != nil {
+			return fmt.Errorf("add tag group %q: %w", group.Name, err)
+		}
+	}
+	for _, change := range plan.EditGroups {
+		if err := r.editTag(change.StrategyID, change.GroupID, change.GroupName, change.Order); err != nil {
+			return fmt.Errorf("edit tag group %q: %w", change.GroupName, err)
+		}
+	}
+	// 同一标签组的新标签合并为一次请求
+	var groupIDs []string
+	groups := make(map[string]TagChange)
+	added := make(map[string][]TagData)
+	for _, change := range plan.AddTags {
+		if _, ok := groups[change.GroupID]; !ok {
+			groupIDs = append(groupIDs, change.GroupID)
+			groups[change.GroupID] = change
+		}
+		added[change.GroupID] = append(added[change.GroupID], TagData{Name: change.Name, Order: change.Order})
+	}
+	for _, groupID := range groupIDs {
+		change := groups[groupID]
+		if err := r.addTags(change.StrategyID, groupID, change.GroupName, 0, added[groupID]); err != nil {
+			return fmt.Errorf("add tags to group %q: %w", change.GroupName, err)
+		}
+	}
+	for _, change := range plan.EditTags {
+		if err := r.editTag(change.StrategyID, change.TagID, change.Name, change.Order); err != nil {
+			return fmt.Errorf("edit tag %q in group %q: %w", change.Name, change.GroupName, err)
+		}
+	}
+	if err := r.deleteTags(plan.DelTags, false); err != nil {
+		return err
+	}
+	return r.deleteTags(plan.DelGroups, true)
+}
+
+// addTags 新建标签组或在已有标签组中新建标签
+func (r *Client) addTags(strategyID int, groupID, groupName string, order int, tags []TagData) error {
+	if strategyID != 0 {
+		req := &AddStrategyTagRequest{StrategyID: strategyID, GroupID: groupID, GroupName: groupName, Order: order}
+		for _, tag := range tags {
+			req.Tag = append(req.Tag, AddStrategyTagRequestItem{Name: tag.Name, Order: tag.Order})
+		}
+		_, err := r.AddStrategyTag(req)
+		return err
+	}
+	req := AddCropTagRequest{GroupID: groupID, GroupName: groupName, Order: order}
+	for _, tag := range tags {
+		req.Tag = append(req.Tag, AddCropTagItem{Name: tag.Name, Order: tag.Order})
+	}
+	_, err := r.AddCropTag(req)
+	return err
+}
+
+// editTag 修改标签或标签组的次序值
+func (r *Client) editTag(strategyID int, id, name string, order int) error {
+	if strategyID != 0 {
+		return r.EditStrategyTag(&EditStrategyTagRequest{ID: id, Name: name, Order: order})
+	}
+	return r.EditCropTag(EditCropTagRequest{ID: id, Name: name, Order: order})
+}
+
+// deleteTags 按企业标签与规则组分别批量删除标签或标签组
+func (r *Client) deleteTags(changes []TagChange, groups bool) error {
+	var strategyIDs []int
+	ids := make(map[int][]string)
+	for _, change := range changes {
+		id := change.TagID
+		if groups {
+			id = change.GroupID
+		}
+		if _, ok := ids[change.StrategyID]; !ok {
+			strategyIDs = append(strategyIDs, change.StrategyID)
+		}
+		ids[change.StrategyID] = append(ids[change.StrategyID], id)
+	}
+	for _, strategyID := range strategyIDs {
+		var err error
+		switch {
+		case strategyID != 0 && groups:
+			err = r.DelStrategyTag(&DelStrategyTagRequest{GroupID: ids[strategyID]})
+		case strategyID != 0:
+			err = r.DelStrategyTag(&DelStrategyTagRequest{TagID: ids[strategyID]})
+		case groups:
+			err = r.DeleteCropTag(DeleteCropTagRequest{GroupID: ids[strategyID]})
+		default:
+			err = r.DeleteCropTag(DeleteCropTagRequest{TagID: ids[strategyID]})
+		}
+		if err != nil {
+			return fmt.Errorf("delete tags of strategy %d: %w", strategyID, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package externalcontact
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/h2non/gock.v1"
+)
+
+// tagGroup 生成标签列表接口返回的标签组，tags依次为标签ID与名称
+func tagGroup(groupID, name string, order int, tags ...string) map[string]interface{} {
+	items := make([]map[string]interface{}, 0, len(tags)/2)
+	for i := 0; i+1 < len(tags); i += 2 {
+		items = append(items, map[string]interface{}{"id": tags[i], "name": tags[i+1], "order": 0})
+	}
+	return map[string]interface{}{"group_id": groupID, "group_name": name, "group_order": order, "order": order, "tag": items}
+}
+
+func TestSyncTags(t *testing.T) {
+	defer gock.Off()
+	client := newTestClient()
+	desired := &TagTaxonomy{Groups: []TagGroupData{
+		{Name: "等级", Order: 2, Tags: []TagData{{Name: "VIP"}, {Name: "新客"}}},
+		{Name: "来源", Tags: []TagData{{Name: "官网"}, {Name: "活动"}}},
+		{Name: "门店", StrategyID: 7, Tags: []TagData{{Name: "上海"}}},
+	}}
+
+	// 企业标签列表包含未声明的规则组9的标签组，删除企业标签时不能删除该标签组
+	mockStrategies := func() {
+		mockExternalContactAPI("customer_strategy/list", `"cursor":"","limit":1000`, map[string]interface{}{"strategy": []map[string]int{{"strategy_id": 7}, {"strategy_id": 9}}})
+		mockExternalContactAPI("get_strategy_tag_list", `"strategy_id":9`, map[string]interface{}{"tag_group": []interface{}{tagGroup("region", "区域", 0, "east", "华东")}})
+	}
+	mockStrategies()
+	mockExternalContactAPI("get_strategy_tag_list", `"strategy_id":7`, map[string]interface{}{})
+	mockExternalContactAPI("get_corp_tag_list", "", map[string]interface{}{"tag_group": []interface{}{
+		tagGroup("level", "等级", 1, "vip", "VIP", "old", "普通"),
+		tagGroup("legacy", "废弃", 0, "legacy_tag", "旧标签"),
+		tagGroup("region", "区域", 0, "east", "华东"),
+	}})
+	mockExternalContactAPI("add_corp_tag", `"group_name":"来源","order":0,"tag":\[\{"name":"官网","order":0\},\{"name":"活动","order":0\}\]`, map[string]interface{}{})
+	mockExternalContactAPI("add_strategy_tag", `"strategy_id":7,"group_id":"","group_name":"门店","order":0,"tag":\[\{"name":"上海"`, map[string]interface{}{})
+	mockExternalContactAPI("edit_corp_tag", `"id":"level","name":"等级","order":2`, map[string]interface{}{})
+	mockExternalContactAPI("add_corp_tag", `"group_id":"level","group_name":"等级","order":0,"tag":\[\{"name":"新客","order":0\}\]`, map[string]interface{}{})
+	mockExternalContactAPI("del_corp_tag", `"tag_id":\["old"\],"group_id":null`, map[string]interface{}{})
+	mockExternalContactAPI("del_corp_tag", `"tag_id":null,"group_id":\["legacy"\]`, map[string]interface{}{})
+	plan, err := client.SyncTags(desired, &TagDiffOptions{Prune: true})
+	assert.Nil(t, err)
+	assert.Len(t, plan.AddGroups, 2)
+	assert.Equal(t, []TagChange{{GroupID: "level", GroupName: "等级", Order: 2}}, plan.EditGroups)
+	assert.Equal(t, []TagChange{{GroupID: "level", GroupName: "等级", TagID: "old", Name: "普通"}}, plan.DelTags)
+	assert.Equal(t, []TagChange{{GroupID: "legacy", GroupName: "废弃"}}, plan.DelGroups)
+	assert.True(t, gock.IsDone())
+
+	// 标签库与目标内容一致时只调用查询接口
+	mockStrategies()
+	mockExternalContactAPI("get_strategy_tag_list", `"strategy_id":7`, map[string]interface{}{"tag_group": []interface{}{tagGroup("store", "门店", 0, "sh", "上海")}})
+	mockExternalContactAPI("get_corp_tag_list", "", map[string]interface{}{"tag_group": []interface{}{
+		tagGroup("level", "等级", 2, "vip", "VIP", "new", "新客"),
+		tagGroup("source", "来源", 0, "web", "官网", "event", "活动"),
+		tagGroup("store", "门店", 0, "sh", "上海"),
+		tagGroup("region", "区域", 0, "east", "华东"),
+	}})
+	plan, err = client.SyncTags(desired, &TagDiffOptions{Prune: true})
+	assert.Nil(t, err)
+	assert.True(t, plan.Empty())
+	assert.True(t, gock.IsDone())
+
+	_, err = DiffTags(&TagTaxonomy{}, &TagTaxonomy{Groups: []TagGroupData{{Name: "空分组"}}}, nil)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,204 @@
+package externalcontact
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/northseadl/wechat/v2/work/material"
+	"github.com/silenceper/wechat/v2/credential"
+)
+
+const (
+	// welcomeCodeValid 欢迎语code的有效期
+	welcomeCodeValid = 20 * time.Second
+	// welcomeMediaTTL 欢迎语附件临时素材的缓存时间，临时素材有效期为3天，提前失效以免发送时素材已过期
+	welcomeMediaTTL = 60 * time.Hour
+	// maxWelcomeAttachments 欢迎语附件的数量上限
+	maxWelcomeAttachments = 9
+)
+
+// ErrWelcomeCodeExpired 欢迎语code已超过20秒有效期
+var ErrWelcomeCodeExpired = errors.New("welcome code expired")
+
+// WelcomeAttachment 欢迎语附件
+type WelcomeAttachment struct {
+	Attachment
+	File string // 本地文件路径，不为空时上传为临时素材，并填充图片、视频、文件的media_id或小程序的pic_media_id
+}
+
+// WelcomeTemplate 欢迎语模板
+type WelcomeTemplate struct {
+	Text        string              // 欢迎语文本，可在BeforeSend中替换客户昵称等变量
+	Attachments []WelcomeAttachment // 欢迎语附件，最多9个
+	Tags        []string            // 添加客户后为客户打上的企业标签ID
+}
+
+// WelcomeOptions 欢迎语流程配置
+type WelcomeOptions struct {
+	Templates map[string]*WelcomeTemplate // 按「联系我」或获客链接的state选择模板
+	Default   *WelcomeTemplate            // 没有匹配的state时使用的模板，为空时不发送
+	// Select 自定义模板选择，返回nil时按Templates与Default选择
+	Select func(msg EventCallbackMessage) (*WelcomeTemplate, error)
+	// BeforeSend 发送前修改欢迎语请求，返回错误时不发送
+	BeforeSend func(msg EventCallbackMessage, req *SendWelcomeMsgRequest) error
+	// AfterSend 发送后回调，err为发送结果
+	AfterSend func(msg EventCallbackMessage, template *WelcomeTemplate, err error)
+}
+
+// WelcomeWorkflow 新客户欢迎语流程，收到添加企业客户事件后按state选择欢迎语模板，在欢迎语code过期前发送，并为客户打上标签
+type WelcomeWorkflow struct {
+	client   *Client
+	material *material.Client
+	options  WelcomeOptions
+	prefix   string
+}
+
+// NewWelcomeWorkflow 创建新客户欢迎语流程，附件文件通过 work/material 上传为临时素材并缓存media_id
+func NewWelcomeWorkflow(client *Client, opts *WelcomeOptions) *WelcomeWorkflow {
+	var options WelcomeOptions
+	if opts != nil {
+		options = *opts
+	}
+	return &WelcomeWorkflow{
+		client:   client,
+		material: material.NewClient(client.Context),
+		options:  options,
+		prefix:   fmt.Sprintf("%swelcome_media_%s_", credential.CacheKeyWorkPrefix, client.CorpID),
+	}
+}
+
+// Handle 处理客户联系回调事件，仅处理添加企业客户事件，其他事件直接返回
+// 事件没有WelcomeCode时（如「联系我」已配置欢迎语）只打标签；欢迎语code过期时返回 ErrWelcomeCodeExpired
+func (w *WelcomeWorkflow) Handle(msg EventCallbackMessage) error {
+	if msg.Event != EventChangeExternalContact || msg.ChangeType != ChangeTypeAddExternalContact {
+		return nil
+	}
+	template, err := w.template(msg)
+	if err != nil || template == nil {
+		return err
+	}
+
+	var sendErr error
+	if msg.WelcomeCode != "" {
+		sendErr = w.send(msg, template)
+		if w.options.AfterSend != nil {
+			w.options.AfterSend(msg, template, sendErr)
+		}
+	}
+	if len(template.Tags) > 0 {
+		err = w.client.MarkTag(MarkTagRequest{UserID: msg.UserID, ExternalUserID: msg.ExternalUserID, AddTag: template.Tags})
+		if err != nil && sendErr == nil {
+			return fmt.Errorf("mark welcome tags: %w", err)
+		}
+	}
+	return sendErr
+}
+
+// Warmup 预先上传全部模板的附件文件，避免首次发送时上传耗时导致欢迎语code过期
+func (w *WelcomeWorkflow) Warmup() error {
+	templates := make([]*WelcomeTemplate, 0, len(w.options.Templates)+1)
+	for _, template := range w.options.Templates {
+		templates = append(templates, template)
+	}
+	if w.options.Default != nil {
+		templates = append(templates, w.options.Default)
+	}
+	for _, template := range templates {
+		if _, err := w.attachments(template); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// template 选择欢迎语模板
+func (w *WelcomeWorkflow) template(msg EventCallbackMessage) (*WelcomeTemplate, error) {
+	if w.options.Select != nil {
+		template, err := w.options.Select(msg)
+		if err != nil || template != nil {
+			return template, err
+		}
+	}
+	if template, ok := w.options.Templates[msg.State]; ok {
+		return template, nil
+	}
+	return w.options.Default, nil
+}
+
+// send 在欢迎语code有效期内发送欢迎语
+func (w *WelcomeWorkflow) send(msg EventCallbackMessage, template *WelcomeTemplate) error {
+	deadline := time.Unix(msg.CreateTime, 0).Add(welcomeCodeValid)
+	if msg.CreateTime > 0 && time.Now().After(deadline) {
+		return ErrWelcomeCodeExpired
+	}
+	attachments, err := w.attachments(template)
+	if err != nil {
+		return err
+	}
+	req := &SendWelcomeMsgRequest{WelcomeCode: msg.WelcomeCode, Text: MsgText{Content: template.Text}, Attachments: attachments}
+	if w.options.BeforeSend != nil {
+		if err = w.options.BeforeSend(msg, req); err != nil {
+			return err
+		}
+	}
+	if msg.CreateTime > 0 && time.Now().After(deadline) {
+		return ErrWelcomeCodeExpired
+	}
+	return w.client.SendWelcomeMsg(req)
+}
+
+// attachments 生成模板的附件，上传附件文件并填充media_id
+func (w *WelcomeWorkflow) attachments(template *WelcomeTemplate) ([]*Attachment, error) {
+	if len(template.Attachments) > maxWelcomeAttachments {
+		return nil, fmt.Errorf("welcome message supports at most %d attachments, got %d", maxWelcomeAttachments, len(template.Attachments))
+	}
+	attachments := make([]*Attachment, 0, len(template.Attachments))
+	for _, item := range template.Attachments {
+		attachment := item.Attachment
+		if item.File != "" {
+			mediaType := attachment.MsgType
+			if mediaType == "miniprogram" {
+				mediaType = "image"
+			}
+			mediaID, err := w.mediaID(item.File, mediaType)
+			if err != nil {
+				return nil, fmt.Errorf("upload welcome attachment %s: %w", item.File, err)
+			}
+			switch attachment.MsgType {
+			case "image":
+				attachment.Image.MediaID = mediaID
+			case "video":
+				attachment.Video.MediaID = mediaID
+			case "file":
+				attachment.File.MediaID = mediaID
+			case "miniprogram":
+				attachment.MiniProgram.PicMediaID = mediaID
+			default:
+				return nil, fmt.Errorf("attachment type %q does not support file upload", attachment.MsgType)
+			}
+		}
+		attachments = append(attachments, &attachment)
+	}
+	return attachments, nil
+}
+
+// mediaID 获取附件文件对应的临时素材media_id，优先使用缓存
+func (w *WelcomeWorkflow) mediaID(filename, mediaType string) (string, error) {
+	key := w.prefix + mediaType + "_" + filename
+	if w.client.Cache != nil {
+		if mediaID, ok := w.client.Cache.Get(key).(string); ok && mediaID != "" {
+			return mediaID, nil
+		}
+	}
+	result, err := w.material.UploadTempFile(filename, mediaType)
+	if err != nil {
+		return "", err
+	}
+	if w.client.Cache != nil {
+		if err = w.client.Cache.Set(key, result.MediaID, welcomeMediaTTL); err != nil {
+			return "", err
+		}
+	}
+	return result.MediaID, nil
+}
@@ -0,0 +1,59 @@
+package externalcontact
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/h2non/gock.v1"
+)
+
+func TestWelcomeWorkflow(t *testing.T) {
+	defer gock.Off()
+	cover := filepath.Join(t.TempDir(), "cover.png")
+	assert.Nil(t, os.WriteFile(cover, []byte("png"), 0o600))
+	workflow := NewWelcomeWorkflow(newTestClient(), &WelcomeOptions{
+		Templates: map[string]*WelcomeTemplate{
+			"promo": {
+				Text: "你好，{name}",
+				Attachments: []WelcomeAttachment{
+					{Attachment: Attachment{MsgType: "miniprogram", MiniProgram: AttachmentMiniProgram{Title: "活动", AppID: "wx1", Page: "pages/index"}}, File: cover},
+				},
+				Tags: []string{"TAG_PROMO"},
+			},
+		},
+		BeforeSend: func(msg EventCallbackMessage, req *SendWelcomeMsgRequest) error {
+			req.Text.Content = "你好，" + msg.ExternalUserID
+			return nil
+		},
+	})
+
+	// 小程序封面只上传一次，第二次发送使用缓存的media_id
+	gock.New("https://qyapi.weixin.qq.com").Post("/cgi-bin/media/upload").MatchParam("type", "image").
+		Reply(200).JSON(map[string]interface{}{"errcode": 0, "type": "image", "media_id": "MEDIA_ID"})
+	gock.New("https://qyapi.weixin.qq.com").Post("/cgi-bin/externalcontact/send_welcome_msg").
+		BodyString(`"welcome_code":"CODE","text":\{"content":"你好，wm1"\}.*"pic_media_id":"MEDIA_ID"`).Times(2).
+		Reply(200).JSON(map[string]interface{}{"errcode": 0})
+	gock.New("https://qyapi.weixin.qq.com").Post("/cgi-bin/externalcontact/mark_tag").
+		BodyString(`"userid":"zhangsan","external_userid":"wm1","add_tag":\["TAG_PROMO"\]`).Times(3).
+		Reply(200).JSON(map[string]interface{}{"errcode": 0})
+
+	msg := EventCallbackMessage{
+		Event: EventChangeExternalContact, ChangeType: ChangeTypeAddExternalContact, CreateTime: time.Now().Unix(),
+		UserID: "zhangsan", ExternalUserID: "wm1", State: "promo", WelcomeCode: "CODE",
+	}
+	assert.Nil(t, workflow.Handle(msg))
+	assert.Nil(t, workflow.Handle(msg))
+
+	// 没有匹配的模板且未配置默认模板时不调用接口
+	msg.State = "unknown"
+	assert.Nil(t, workflow.Handle(msg))
+
+	// 欢迎语code过期时不发送，仍为客户打标签
+	msg.State = "promo"
+	msg.CreateTime = time.Now().Add(-time.Minute).Unix()
+	assert.Equal(t, ErrWelcomeCodeExpired, workflow.Handle(msg))
+	assert.True(t, gock.IsDone())
+}
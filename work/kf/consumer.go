@@ -0,0 +1,306 @@
+package kf
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/northseadl/wechat/v2/work/kf/syncmsg"
+	"github.com/silenceper/wechat/v2/cache"
+	"github.com/silenceper/wechat/v2/credential"
+)
+
+const (
+	// defaultCursorTTL 默认的拉取游标缓存有效期
+	defaultCursorTTL = 30 * 24 * time.Hour
+	// defaultDedupTTL 默认的消息去重记录有效期，sync_msg接口只能拉取最近3天的消息
+	defaultDedupTTL = 3 * 24 * time.Hour
+	// defaultSyncMsgLimit 默认每次拉取的消息条数，也是sync_msg接口允许的最大值
+	defaultSyncMsgLimit = 1000
+)
+
+// CursorStore 消息拉取游标的存储，按客服帐号保存sync_msg返回的next_cursor
+type CursorStore interface {
+	GetCursor(openKFID string) (string, error)
+	SetCursor(openKFID string, cursor string) error
+}
+
+// MessageDeduper 已处理消息的去重记录存储
+type MessageDeduper interface {
+	// IsDuplicate msgid对应的消息是否已处理
+	IsDuplicate(msgID string) (bool, error)
+	// MarkProcessed 记录msgid对应的消息已处理，ttl为记录的有效期
+	MarkProcessed(msgID string, ttl time.Duration) error
+}
+
+// CacheCursorStore 基于cache.Cache的游标存储，同时实现了MessageDeduper
+type CacheCursorStore struct {
+	cache  cache.Cache
+	prefix string
+	ttl    time.Duration
+}
+
+// NewCacheCursorStore 创建基于cache.Cache的游标存储，prefix为缓存key的前缀，ttl<=0时使用默认的30天
+func NewCacheCursorStore(c cache.Cache, prefix string, ttl time.Duration) *CacheCursorStore {
+	if ttl <= 0 {
+		ttl = defaultCursorTTL
+	}
+	return &CacheCursorStore{cache: c, prefix: prefix, ttl: ttl}
+}
+
+// GetCursor 获取客服帐号的游标，不存在时返回空字符串
+func (s *CacheCursorStore) GetCursor(openKFID string) (string, error) {
+	switch val := s.cache.Get(s.prefix + openKFID).(type) {
+	case nil:
+		return "", nil
+	case string:
+		return val, nil
+	case []byte:
+		return string(val), nil
+	default:
+		return "", fmt.Errorf("unexpected cursor value %v of type %T", val, val)
+	}
+}
+
+// SetCursor 保存客服帐号的游标
+func (s *CacheCursorStore) SetCursor(openKFID string, cursor string) error {
+	return s.cache.Set(s.prefix+openKFID, cursor, s.ttl)
+}
+
+// IsDuplicate msgid对应的消息是否已处理
+func (s *CacheCursorStore) IsDuplicate(msgID string) (bool, error) {
+	return s.cache.IsExist(s.prefix + "msgid_" + msgID), nil
+}
+
+// MarkProcessed 记录msgid对应的消息已处理
+func (s *CacheCursorStore) MarkProcessed(msgID string, ttl time.Duration) error {
+	return s.cache.Set(s.prefix+"msgid_"+msgID, 1, ttl)
+}
+
+// MessageHandler 消息处理函数，返回错误时停止本次拉取，游标不会前进，消息会在下次拉取时重新投递
+type MessageHandler func(msg syncmsg.Message) error
+
+// ConsumerOptions 消息消费者配置
+type ConsumerOptions struct {
+	Store CursorStore // 游标存储，默认使用客服实例的cache
+	// Dedup 按msgid去重的记录存储，默认使用Store（Store实现了MessageDeduper时），否则使用客服实例的cache
+	Dedup       MessageDeduper
+	Limit       uint          // 每次拉取的消息条数，默认1000
+	VoiceFormat uint          // 语音消息格式，0-Amr 1-Silk
+	DedupTTL    time.Duration // 按msgid去重的记录有效期，默认3天
+}
+
+// Consumer 微信客服消息消费者，收到kf_msg_or_event回调后拉取对应客服帐号的全部消息，持久化游标并按msgid去重后分发给处理函数
+type Consumer struct {
+	client         *Client
+	store          CursorStore
+	limit          uint
+	voiceFormat    uint
+	dedup          MessageDeduper
+	dedupTTL       time.Duration
+	handlers       map[string][]MessageHandler
+	eventHandlers  map[string][]MessageHandler
+	defaultHandler MessageHandler
+	allHandlers    []MessageHandler
+
+	mu    sync.Mutex
+	locks map[string]*sync.Mutex
+}
+
+// NewConsumer 创建消息消费者
+func NewConsumer(client *Client, opts *ConsumerOptions) *Consumer {
+	var options ConsumerOptions
+	if opts != nil {
+		options = *opts
+	}
+	prefix := fmt.Sprintf("%skf_%s_", credential.CacheKeyWorkPrefix, client.corpID)
+	if options.Store == nil {
+		options.Store = NewCacheCursorStore(client.cache, prefix+"cursor_", 0)
+	}
+	if options.Dedup == nil {
+		if dedup, ok := options.Store.(MessageDeduper); ok {
+			options.Dedup = dedup
+		} else {
+			options.Dedup = NewCacheCursorStore(client.cache, prefix, 0)
+		}
+	}
+	if options.Limit == 0 {
+		options.Limit = defaultSyncMsgLimit
+	}
+	if options.DedupTTL <= 0 {
+		options.DedupTTL = defaultDedupTTL
+	}
+	return &Consumer{
+		client:        client,
+		store:         options.Store,
+		limit:         options.Limit,
+		voiceFormat:   options.VoiceFormat,
+		dedup:         options.Dedup,
+		dedupTTL:      options.DedupTTL,
+		handlers:      make(map[string][]MessageHandler),
+		eventHandlers: make(map[string][]MessageHandler),
+		locks:         make(map[string]*sync.Mutex),
+	}
+}
+
+// Handle 注册消息处理函数，msgType为消息类型，如text、image
+func (c *Consumer) Handle(msgType string, handler MessageHandler) {
+	c.handlers[msgType] = append(c.handlers[msgType], handler)
+}
+
+// HandleEvent 注册事件处理函数，eventType为事件类型，如enter_session、session_status_change
+func (c *Consumer) HandleEvent(eventType string, handler MessageHandler) {
+	c.eventHandlers[eventType] = append(c.eventHandlers[eventType], handler)
+}
+
+// HandleDefault 注册默认处理函数，没有匹配的处理函数的消息会交给它处理
+func (c *Consumer) HandleDefault(handler MessageHandler) {
+	c.defaultHandler = handler
+}
+
+// HandleAll 注册对全部消息与事件生效的处理函数，在按类型注册的处理函数之前执行
+func (c *Consumer) HandleAll(handler MessageHandler) {
+	c.allHandlers = append(c.allHandlers, handler)
+}
+
+// OnText 注册文本消息处理函数
+func (c *Consumer) OnText(handler func(msg syncmsg.Text) error) {
+	c.Handle("text", func(msg syncmsg.Message) error {
+		info, err := msg.GetTextMessage()
+		if err != nil {
+			return err
+		}
+		return handler(info)
+	})
+}
+
+// OnEnterSession 注册用户进入会话事件处理函数
+func (c *Consumer) OnEnterSession(handler func(event syncmsg.EnterSessionEvent) error) {
+	c.HandleEvent("enter_session", func(msg syncmsg.Message) error {
+		info, err := msg.GetEnterSessionEvent()
+		if err != nil {
+			return err
+		}
+		return handler(info)
+	})
+}
+
+// OnMsgSendFail 注册消息发送失败事件处理函数
+func (c *Consumer) OnMsgSendFail(handler func(event syncmsg.MsgSendFailEvent) error) {
+	c.HandleEvent("msg_send_fail", func(msg syncmsg.Message) error {
+		info, err := msg.GetMsgSendFailEvent()
+		if err != nil {
+			return err
+		}
+		return handler(info)
+	})
+}
+
+// OnReceptionistStatusChange 注册接待人员接待状态变更事件处理函数
+func (c *Consumer) OnReceptionistStatusChange(handler func(event syncmsg.ReceptionistStatusChangeEvent) error) {
+	c.HandleEvent("servicer_status_change", func(msg syncmsg.Message) error {
+		info, err := msg.GetReceptionistStatusChangeEvent()
+		if err != nil {
+			return err
+		}
+		return handler(info)
+	})
+}
+
+// OnSessionStatusChange 注册会话状态变更事件处理函数
+func (c *Consumer) OnSessionStatusChange(handler func(event syncmsg.SessionStatusChangeEvent) error) {
+	c.HandleEvent("session_status_change", func(msg syncmsg.Message) error {
+		info, err := msg.GetSessionStatusChangeEvent()
+		if err != nil {
+			return err
+		}
+		return handler(info)
+	})
+}
+
+// Consume 处理kf_msg_or_event回调，拉取回调对应客服帐号的全部新消息
+func (c *Consumer) Consume(message CallbackMessage) error {
+	return c.Pull(message.OpenKfID, message.Token)
+}
+
+// Pull 从已保存的游标开始拉取客服帐号的全部新消息，token为回调事件中的token，可为空
+// 同一客服帐号的拉取会串行执行
+func (c *Consumer) Pull(openKFID, token string) error {
+	lock := c.lock(openKFID)
+	lock.Lock()
+	defer lock.Unlock()
+
+	cursor, err := c.store.GetCursor(openKFID)
+	if err != nil {
+		return err
+	}
+	for {
+		result, err := c.client.SyncMsg(SyncMsgOptions{
+			Cursor:      cursor,
+			Token:       token,
+			Limit:       c.limit,
+			VoiceFormat: c.voiceFormat,
+			OpenKfID:    openKFID,
+		})
+		if err != nil {
+			return err
+		}
+		for _, msg := range result.MsgList {
+			if err = c.dispatch(msg); err != nil {
+				return err
+			}
+		}
+		if result.NextCursor != "" {
+			cursor = result.NextCursor
+			if err = c.store.SetCursor(openKFID, cursor); err != nil {
+				return err
+			}
+		}
+		// has_more为1时msg_list也可能为空，只能根据has_more判断是否继续拉取
+		if result.HasMore != 1 {
+			return nil
+		}
+	}
+}
+
+// dispatch 去重后将消息分发给对应的处理函数
+func (c *Consumer) dispatch(msg syncmsg.Message) error {
+	if msg.MsgID != "" {
+		duplicate, err := c.dedup.IsDuplicate(msg.MsgID)
+		if err != nil || duplicate {
+			return err
+		}
+	}
+
+	typed := c.handlers[msg.MsgType]
+	if msg.MsgType == "event" {
+		typed = c.eventHandlers[msg.EventType]
+	}
+	if len(typed) == 0 && c.defaultHandler != nil {
+		typed = []MessageHandler{c.defaultHandler}
+	}
+	handlers := make([]MessageHandler, 0, len(c.allHandlers)+len(typed))
+	handlers = append(append(handlers, c.allHandlers...), typed...)
+	for _, handler := range handlers {
+		if err := handler(msg); err != nil {
+			return fmt.Errorf("handle kf message %s: %w", msg.MsgID, err)
+		}
+	}
+
+	if msg.MsgID == "" {
+		return nil
+	}
+	return c.dedup.MarkProcessed(msg.MsgID, c.dedupTTL)
+}
+
+// lock 获取客服帐号对应的锁
+func (c *Consumer) lock(openKFID string) *sync.Mutex {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	lock, ok := c.locks[openKFID]
+	if !ok {
+		lock = &sync.Mutex{}
+		c.locks[openKFID] = lock
+	}
+	return lock
+}
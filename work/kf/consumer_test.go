@@ -0,0 +1,92 @@
+package kf
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/h2non/gock.v1"
+
+	"github.com/northseadl/wechat/v2/internal/worktest"
+	"github.com/northseadl/wechat/v2/work/kf/syncmsg"
+)
+
+// newTestClient 创建使用内存缓存的客服客户端，并模拟获取access_token
+func newTestClient(t *testing.T) *Client {
+	worktest.MockAccessToken()
+	client, err := NewClient(worktest.NewConfig())
+	assert.Nil(t, err)
+	return client
+}
+
+func mockSyncMsg(cursor string, nextCursor string, hasMore int, msgList ...map[string]interface{}) {
+	gock.New("https://qyapi.weixin.qq.com").
+		Post("/cgi-bin/kf/sync_msg").
+		BodyString(`"cursor":"` + cursor + `"`).
+		Reply(200).
+		JSON(map[string]interface{}{"errcode": 0, "next_cursor": nextCursor, "has_more": hasMore, "msg_list": msgList})
+}
+
+func textMsg(msgID string) map[string]interface{} {
+	return map[string]interface{}{"msgid": msgID, "open_kfid": "kf1", "external_userid": "wm1", "origin": 3, "msgtype": "text", "text": map[string]string{"content": msgID}}
+}
+
+// memoryConsumerStore 同时保存游标与去重记录的自定义存储
+type memoryConsumerStore struct {
+	cursors   map[string]string
+	processed map[string]bool
+}
+
+func (s *memoryConsumerStore) GetCursor(openKFID string) (string, error) {
+	return s.cursors[openKFID], nil
+}
+
+func (s *memoryConsumerStore) SetCursor(openKFID string, cursor string) error {
+	s.cursors[openKFID] = cursor
+	return nil
+}
+
+func (s *memoryConsumerStore) IsDuplicate(msgID string) (bool, error) {
+	return s.processed[msgID], nil
+}
+
+func (s *memoryConsumerStore) MarkProcessed(msgID string, ttl time.Duration) error {
+	s.processed[msgID] = true
+	return nil
+}
+
+func TestConsumerPull(t *testing.T) {
+	defer gock.Off()
+	client := newTestClient(t)
+	store := &memoryConsumerStore{cursors: make(map[string]string), processed: make(map[string]bool)}
+	consumer := NewConsumer(client, &ConsumerOptions{Store: store})
+	var received []string
+	consumer.HandleAll(func(msg syncmsg.Message) error {
+		received = append(received, msg.MsgID)
+		return nil
+	})
+	var entered int
+	consumer.OnEnterSession(func(event syncmsg.EnterSessionEvent) error {
+		entered++
+		return nil
+	})
+
+	// has_more为1时继续拉取，第二页重复的消息只处理一次
+	gock.New("https://qyapi.weixin.qq.com").Post("/cgi-bin/kf/sync_msg").BodyString(`"cursor":"","token":"TOKEN","limit":1000`).
+		Reply(200).JSON(map[string]interface{}{"errcode": 0, "next_cursor": "c1", "has_more": 1, "msg_list": []interface{}{textMsg("m1"), textMsg("m2")}})
+	mockSyncMsg("c1", "c2", 0, textMsg("m2"), map[string]interface{}{
+		"msgid": "m3", "msgtype": "event", "event": map[string]string{"event_type": "enter_session", "open_kfid": "kf1", "external_userid": "wm1"},
+	})
+	assert.Nil(t, consumer.Consume(CallbackMessage{OpenKfID: "kf1", Token: "TOKEN"}))
+	assert.Equal(t, []string{"m1", "m2", "m3"}, received)
+	assert.Equal(t, 1, entered)
+	assert.Equal(t, "c2", store.cursors["kf1"])
+	assert.True(t, store.processed["m3"])
+
+	// 从保存的游标继续拉取，next_cursor为空时游标保持不变
+	mockSyncMsg("c2", "", 0, textMsg("m3"), textMsg("m4"))
+	assert.Nil(t, consumer.Pull("kf1", ""))
+	assert.Equal(t, []string{"m1", "m2", "m3", "m4"}, received)
+	assert.Equal(t, "c2", store.cursors["kf1"])
+	assert.True(t, gock.IsDone())
+}
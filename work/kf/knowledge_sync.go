@@ -0,0 +1,543 @@
+package kf
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// knowledgeListLimit 分组与问答列表每次拉取的数量上限
+const knowledgeListLimit = 1000
+
+// knowledgeCSVHeader CSV格式的表头，每行一条问答，相似问题以换行分隔，附件为JSON数组；问题为空的行表示没有问答的分组
+var knowledgeCSVHeader = []string{"group", "intent_id", "question", "similar_questions", "answer", "attachments"}
+
+// KnowledgeBase 知识库内容，用于导入导出与同步
+type KnowledgeBase struct {
+	Groups []KnowledgeGroupData `json:"groups"`
+}
+
+// KnowledgeGroupData 知识库分组及其问答
+type KnowledgeGroupData struct {
+	GroupID   string                `json:"group_id,omitempty"`   // 分组ID，为空时按分组名匹配
+	Name      string                `json:"name"`                 // 分组名
+	IsDefault bool                  `json:"is_default,omitempty"` // 是否为默认分组，默认分组不能删除与重命名
+	Intents   []KnowledgeIntentData `json:"intents,omitempty"`    // 分组下的问答
+}
+
+// KnowledgeIntentData 知识库问答，目前每个问答只支持一个回答
+type KnowledgeIntentData struct {
+	IntentID         string                `json:"intent_id,omitempty"`         // 问答ID，为空时按分组与主问题匹配
+	Question         string                `json:"question"`                    // 主问题
+	SimilarQuestions []string              `json:"similar_questions,omitempty"` // 相似问题
+	Answer           string                `json:"answer"`                      // 回答文本
+	Attachments      []KnowledgeAttachment `json:"attachments,omitempty"`       // 回答附件
+}
+
+// KnowledgeAttachment 回答附件
+// 问答列表接口只返回图片与视频的文件名，导出的图片与视频附件没有MediaID，导入前需要上传素材并填写MediaID；
+// 比较差异时图片与视频附件只比较Name，Name为空时只比较类型
+type KnowledgeAttachment struct {
+	MsgType      string `json:"msgtype"`                  // 附件类型：image、video、link、miniprogram
+	MediaID      string `json:"media_id,omitempty"`       // 图片、视频的media_id
+	Name         string `json:"name,omitempty"`           // 图片、视频的文件名
+	Title        string `json:"title,omitempty"`          // 链接、小程序的标题
+	PicURL       string `json:"picurl,omitempty"`         // 链接的图片
+	Desc         string `json:"desc,omitempty"`           // 链接的描述
+	URL          string `json:"url,omitempty"`            // 链接的地址
+	ThumbMediaID string `json:"thumb_media_id,omitempty"` // 小程序的封面media_id
+	AppID        string `json:"appid,omitempty"`          // 小程序的appid
+	PagePath     string `json:"pagepath,omitempty"`       // 小程序的页面路径
+}
+
+// ReadKnowledgeJSON 从JSON读取知识库内容
+func ReadKnowledgeJSON(r io.Reader) (*KnowledgeBase, error) {
+	kb := &KnowledgeBase{}
+	if err := json.NewDecoder(r).Decode(kb); err != nil {
+		return nil, err
+	}
+	return kb, nil
+}
+
+// WriteJSON 将知识库内容写为JSON
+func (kb *KnowledgeBase) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(kb)
+}
+
+// ReadKnowledgeCSV 从CSV读取知识库内容，第一行为表头，同名分组的问答按出现顺序合并
+func ReadKnowledgeCSV(r io.Reader) (*KnowledgeBase, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = len(knowledgeCSVHeader)
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return &KnowledgeBase{}, nil
+	}
+	if strings.Join(records[0], ",") != strings.Join(knowledgeCSVHeader, ",") {
+		return nil, fmt.Errorf("invalid csv header %q, want %q", records[0], knowledgeCSVHeader)
+	}
+
+	kb := &KnowledgeBase{}
+	groups := make(map[string]int)
+	for i, record := range records[1:] {
+		name := strings.TrimSpace(record[0])
+		if name == "" {
+			return nil, fmt.Errorf("line %d: group is required", i+2)
+		}
+		index, ok := groups[name]
+		if !ok {
+			index = len(kb.Groups)
+			groups[name] = index
+			kb.Groups = append(kb.Groups, KnowledgeGroupData{Name: name})
+		}
+		if record[2] == "" {
+			continue
+		}
+		intent := KnowledgeIntentData{IntentID: record[1], Question: record[2], Answer: record[4]}
+		for _, question := range strings.Split(record[3], "\n") {
+			if question = strings.TrimSpace(question); question != "" {
+				intent.SimilarQuestions = append(intent.SimilarQuestions, question)
+			}
+		}
+		if record[5] != "" {
+			if err = json.Unmarshal([]byte(record[5]), &intent.Attachments); err != nil {
+				return nil, fmt.Errorf("line %d: attachments: %w", i+2, err)
+			}
+		}
+		kb.Groups[index].Intents = append(kb.Groups[index].Intents, intent)
+	}
+	return kb, nil
+}
+
+// WriteCSV 将知识库内容写为CSV
+func (kb *KnowledgeBase) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(knowledgeCSVHeader); err != nil {
+		return err
+	}
+	for _, group := range kb.Groups {
+		if len(group.Intents) == 0 {
+			if err := writer.Write([]string{group.Name, "", "", "", "", ""}); err != nil {
+				return err
+			}
+		}
+		for _, intent := range group.Intents {
+			var attachments string
+			if len(intent.Attachments) > 0 {
+				data, err := json.Marshal(intent.Attachments)
+				if err != nil {
+					return err
+				}
+				attachments = string(data)
+			}
+			record := []string{group.Name, intent.IntentID, intent.Question,
+				strings.Join(intent.SimilarQuestions, "\n"), intent.Answer, attachments}
+			if err := writer.Write(record); err != nil {
+				return err
+			}
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// ExportKnowledge 分页拉取知识库的全部分组与问答
+func (r *Client) ExportKnowledge() (*KnowledgeBase, error) {
+	kb := &KnowledgeBase{}
+	groups := make(map[string]int)
+	var cursor string
+	for {
+		result, err := r.ListKnowledgeGroup(&ListKnowledgeGroupRequest{Cursor: cursor, Limit: knowledgeListLimit})
+		if err != nil {
+			return nil, err
+		}
+		for _, group := range result.GroupList {
+			groups[group.GroupID] = len(kb.Groups)
+			kb.Groups = append(kb.Groups, KnowledgeGroupData{GroupID: group.GroupID, Name: group.Name, IsDefault: group.IsDefault == 1})
+		}
+		if result.HasMore != 1 {
+			break
+		}
+		cursor = result.NextCursor
+	}
+
+	cursor = ""
+	for {
+		result, err := r.ListKnowledgeIntent(&ListKnowledgeIntentRequest{Cursor: cursor, Limit: knowledgeListLimit})
+		if err != nil {
+			return nil, err
+		}
+		for _, intent := range result.IntentList {
+			index, ok := groups[intent.GroupID]
+			if !ok {
+				return nil, fmt.Errorf("intent %s belongs to unknown group %s", intent.IntentID, intent.GroupID)
+			}
+			kb.Groups[index].Intents = append(kb.Groups[index].Intents, intentData(intent))
+		}
+		if result.HasMore != 1 {
+			break
+		}
+		cursor = result.NextCursor
+	}
+	return kb, nil
+}
+
+// intentData 将问答列表返回的问答转换为知识库问答
+func intentData(intent KnowledgeIntent) KnowledgeIntentData {
+	data := KnowledgeIntentData{IntentID: intent.IntentID, Question: intent.Question.Text.Content}
+	for _, item := range intent.SimilarQuestions.Items {
+		data.SimilarQuestions = append(data.SimilarQuestions, item.Text.Content)
+	}
+	if len(intent.Answers) == 0 {
+		return data
+	}
+	answer := intent.Answers[0]
+	data.Answer = answer.Text.Content
+	for _, attachment := range answer.Attachments {
+		item := KnowledgeAttachment{MsgType: attachment.MsgType}
+		switch attachment.MsgType {
+		case "image":
+			item.Name = attachment.Image.Name
+		case "video":
+			item.Name = attachment.Video.Name
+		case "link":
+			item.Title, item.PicURL, item.Desc, item.URL = attachment.Link.Title, attachment.Link.PicURL, attachment.Link.Desc, attachment.Link.URL
+		case "miniprogram":
+			item.Title, item.AppID, item.PagePath = attachment.MiniProgram.Title, attachment.MiniProgram.AppID, attachment.MiniProgram.PagePath
+		}
+		data.Attachments = append(data.Attachments, item)
+	}
+	return data
+}
+
+// KnowledgeDiffOptions 知识库差异比较配置
+type KnowledgeDiffOptions struct {
+	Prune bool // 是否删除目标内容中不存在的分组与问答，默认分组不会被删除
+}
+
+// KnowledgeIntentChange 问答变更
+type KnowledgeIntentChange struct {
+	GroupID   string              // 问答所属分组ID，分组需要新建时为空
+	GroupName string              // 问答所属分组名
+	Intent    KnowledgeIntentData // 问答内容，修改与删除时包含IntentID
+}
+
+// KnowledgePlan 将知识库同步到目标内容需要执行的变更
+type KnowledgePlan struct {
+	AddGroups    []string                   // 需要新建的分组名
+	RenameGroups []ModKnowledgeGroupRequest // 需要重命名的分组
+	AddIntents   []KnowledgeIntentChange    // 需要新建的问答
+	ModIntents   []KnowledgeIntentChange    // 需要修改的问答
+	DelIntents   []KnowledgeIntentChange    // 需要删除的问答
+	DelGroups    []KnowledgeGroupData       // 需要删除的分组，仅Prune时生成
+}
+
+// Empty 知识库是否已与目标内容一致
+func (p *KnowledgePlan) Empty() bool {
+	return len(p.AddGroups)+len(p.RenameGroups)+len(p.AddIntents)+len(p.ModIntents)+len(p.DelIntents)+len(p.DelGroups) == 0
+}
+
+// intentRef 当前知识库中的问答及其所属分组
+type intentRef struct {
+	group  *KnowledgeGroupData
+	intent KnowledgeIntentData
+}
+
+// knowledgeDiff 比较知识库差异的中间状态
+type knowledgeDiff struct {
+	plan              *KnowledgePlan
+	groupsByID        map[string]*KnowledgeGroupData
+	groupsByName      map[string]*KnowledgeGroupData
+	intentsByID       map[string]intentRef
+	intentsByQuestion map[string]intentRef
+	keptGroups        map[string]bool
+	keptIntents       map[string]bool
+}
+
+// DiffKnowledge 比较当前知识库与目标内容，生成同步计划
+// 分组按GroupID匹配，没有GroupID时按分组名匹配；问答按IntentID匹配，没有IntentID时按所属分组与主问题匹配。
+// 修改问答接口不支持变更分组，匹配到的问答分组不同时会删除后重新添加
+func DiffKnowledge(current, desired *KnowledgeBase, opts *KnowledgeDiffOptions) (*KnowledgePlan, error) {
+	d := &knowledgeDiff{
+		plan:              &KnowledgePlan{},
+		groupsByID:        make(map[string]*KnowledgeGroupData),
+		groupsByName:      make(map[string]*KnowledgeGroupData),
+		intentsByID:       make(map[string]intentRef),
+		intentsByQuestion: make(map[string]intentRef),
+		keptGroups:        make(map[string]bool),
+		keptIntents:       make(map[string]bool),
+	}
+	for i := range current.Groups {
+		group := &current.Groups[i]
+		d.groupsByID[group.GroupID] = group
+		d.groupsByName[group.Name] = group
+		for _, intent := range group.Intents {
+			d.intentsByID[intent.IntentID] = intentRef{group, intent}
+			d.intentsByQuestion[group.GroupID+"\x00"+intent.Question] = intentRef{group, intent}
+		}
+	}
+
+	seen := make(map[string]bool)
+	for _, want := range desired.Groups {
+		if want.Name == "" {
+			return nil, fmt.Errorf("knowledge group name is required")
+		}
+		if seen[want.Name] {
+			return nil, fmt.Errorf("duplicate knowledge group %q", want.Name)
+		}
+		seen[want.Name] = true
+		groupID, err := d.group(want)
+		if err != nil {
+			return nil, err
+		}
+		if err = d.intents(want, groupID); err != nil {
+			return nil, err
+		}
+	}
+	if opts != nil && opts.Prune {
+		d.prune(current)
+	}
+	return d.plan, nil
+}
+
+// group 匹配目标分组，返回当前分组ID，分组需要新建时返回空字符串
+func (d *knowledgeDiff) group(want KnowledgeGroupData) (string, error) {
+	group := d.groupsByName[want.Name]
+	if want.GroupID != "" {
+		if group = d.groupsByID[want.GroupID]; group == nil {
+			return "", fmt.Errorf("knowledge group %s not found", want.GroupID)
+		}
+	}
+	if group == nil {
+		d.plan.AddGroups = append(d.plan.AddGroups, want.Name)
+		return "", nil
+	}
+	if group.Name != want.Name {
+		if group.IsDefault {
+			return "", fmt.Errorf("default knowledge group %q can not be renamed", group.Name)
+		}
+		d.plan.RenameGroups = append(d.plan.RenameGroups, ModKnowledgeGroupRequest{GroupID: group.GroupID, Name: want.Name})
+	}
+	d.keptGroups[group.GroupID] = true
+	return group.GroupID, nil
+}
+
+// intents 比较目标分组下的问答
+func (d *knowledgeDiff) intents(want KnowledgeGroupData, groupID string) error {
+	seen := make(map[string]bool)
+	for _, intent := range want.Intents {
+		if intent.Question == "" {
+			return fmt.Errorf("knowledge group %q: question is required", want.Name)
+		}
+		if seen[intent.Question] {
+			return fmt.Errorf("knowledge group %q: duplicate question %q", want.Name, intent.Question)
+		}
+		seen[intent.Question] = true
+
+		change := KnowledgeIntentChange{GroupID: groupID, GroupName: want.Name, Intent: intent}
+		change.Intent.IntentID = ""
+		ref, ok := d.intentsByQuestion[groupID+"\x00"+intent.Question]
+		if intent.IntentID != "" {
+			if ref, ok = d.intentsByID[intent.IntentID]; !ok {
+				return fmt.Errorf("knowledge intent %s not found", intent.IntentID)
+			}
+		}
+		switch {
+		case !ok:
+			d.plan.AddIntents = append(d.plan.AddIntents, change)
+		case ref.group.GroupID != groupID:
+			// 修改问答接口不支持变更分组，删除后在新分组中重新添加
+			d.keptIntents[ref.intent.IntentID] = true
+			d.plan.DelIntents = append(d.plan.DelIntents, KnowledgeIntentChange{GroupID: ref.group.GroupID, GroupName: ref.group.Name, Intent: ref.intent})
+			d.plan.AddIntents = append(d.plan.AddIntents, change)
+		default:
+			d.keptIntents[ref.intent.IntentID] = true
+			if !intentEqual(ref.intent, intent) {
+				change.Intent.IntentID = ref.intent.IntentID
+				d.plan.ModIntents = append(d.plan.ModIntents, change)
+			}
+		}
+	}
+	return nil
+}
+
+// prune 删除目标内容中不存在的问答与非默认分组
+func (d *knowledgeDiff) prune(current *KnowledgeBase) {
+	for _, group := range current.Groups {
+		for _, intent := range group.Intents {
+			if !d.keptIntents[intent.IntentID] {
+				d.plan.DelIntents = append(d.plan.DelIntents, KnowledgeIntentChange{GroupID: group.GroupID, GroupName: group.Name, Intent: intent})
+			}
+		}
+		if !group.IsDefault && !d.keptGroups[group.GroupID] {
+			d.plan.DelGroups = append(d.plan.DelGroups, group)
+		}
+	}
+}
+
+// SyncKnowledge 导出当前知识库，与目标内容比较后执行同步，返回执行的同步计划；内容一致时不会调用任何修改接口
+func (r *Client) SyncKnowledge(desired *KnowledgeBase, opts *KnowledgeDiffOptions) (*KnowledgePlan, error) {
+	current, err := r.ExportKnowledge()
+	if err != nil {
+		return nil, err
+	}
+	plan, err := DiffKnowledge(current, desired, opts)
+	if err != nil {
+		return nil, err
+	}
+	return plan, r.ApplyKnowledgePlan(plan)
+}
+
+// ApplyKnowledgePlan 执行同步计划，执行前会校验新建与修改的问答附件是否包含media_id，
+// 按新建分组、重命名分组、删除问答、新建问答、修改问答、删除分组的顺序执行
+func (r *Client) ApplyKnowledgePlan(plan *KnowledgePlan) error {
+	for _, changes := range [][]KnowledgeIntentChange{plan.AddIntents, plan.ModIntents} {
+		for _, change := range changes {
+			if err := change.Intent.validate(); err != nil {
+				return fmt.Errorf("knowledge group %q, question %q: %w", change.GroupName, change.Intent.Question, err)
+			}
+		}
+	}
+
+	groupIDs := make(map[string]string)
+	for _, name := range plan.AddGroups {
+		result, err := r.AddKnowledgeGroup(&AddKnowledgeGroupRequest{Name: name})
+		if err != nil {
+			return fmt.Errorf("add knowledge group %q: %w", name, err)
+		}
+		groupIDs[name] = result.GroupID
+	}
+	for i := range plan.RenameGroups {
+		if err := r.ModKnowledgeGroup(&plan.RenameGroups[i]); err != nil {
+			return fmt.Errorf("rename knowledge group %s: %w", plan.RenameGroups[i].GroupID, err)
+		}
+	}
+	// 先删除问答，避免在新分组中添加相同的主问题时冲突
+	for _, change := range plan.DelIntents {
+		if err := r.DelKnowledgeIntent(&DelKnowledgeIntentRequest{IntentID: change.Intent.IntentID}); err != nil {
+			return fmt.Errorf("delete knowledge intent %s: %w", change.Intent.IntentID, err)
+		}
+	}
+	for _, change := range plan.AddIntents {
+		groupID := change.GroupID
+		if groupID == "" {
+			groupID = groupIDs[change.GroupName]
+		}
+		question, similar, answers := change.Intent.request()
+		_, err := r.AddKnowledgeIntent(&AddKnowledgeIntentRequest{GroupID: groupID, Question: question, SimilarQuestions: similar, Answers: answers})
+		if err != nil {
+			return fmt.Errorf("add knowledge intent %q: %w", change.Intent.Question, err)
+		}
+	}
+	for _, change := range plan.ModIntents {
+		question, similar, answers := change.Intent.request()
+		err := r.ModKnowledgeIntent(&ModKnowledgeIntentRequest{IntentID: change.Intent.IntentID, Question: question, SimilarQuestions: similar, Answers: answers})
+		if err != nil {
+			return fmt.Errorf("modify knowledge intent %s: %w", change.Intent.IntentID, err)
+		}
+	}
+	for _, group := range plan.DelGroups {
+		if err := r.DelKnowledgeGroup(&DelKnowledgeGroupRequest{GroupID: group.GroupID}); err != nil {
+			return fmt.Errorf("delete knowledge group %s: %w", group.GroupID, err)
+		}
+	}
+	return nil
+}
+
+// validate 校验问答附件是否包含接口要求的字段
+func (i KnowledgeIntentData) validate() error {
+	for _, attachment := range i.Attachments {
+		switch attachment.MsgType {
+		case "image", "video":
+			if attachment.MediaID == "" {
+				return fmt.Errorf("%s attachment %q requires media_id", attachment.MsgType, attachment.Name)
+			}
+		case "link":
+			if attachment.Title == "" || attachment.URL == "" {
+				return fmt.Errorf("link attachment requires title and url")
+			}
+		case "miniprogram":
+			if attachment.ThumbMediaID == "" || attachment.AppID == "" || attachment.PagePath == "" {
+				return fmt.Errorf("miniprogram attachment requires thumb_media_id, appid and pagepath")
+			}
+		default:
+			return fmt.Errorf("unsupported attachment type %q", attachment.MsgType)
+		}
+	}
+	return nil
+}
+
+// request 转换为添加与修改问答的请求参数
+func (i KnowledgeIntentData) request() (IntentQuestion, IntentSimilarQuestions, []IntentAnswerReq) {
+	question := IntentQuestion{Text: IntentQuestionText{Content: i.Question}}
+	similar := IntentSimilarQuestions{Items: make([]IntentQuestion, 0, len(i.SimilarQuestions))}
+	for _, item := range i.SimilarQuestions {
+		similar.Items = append(similar.Items, IntentQuestion{Text: IntentQuestionText{Content: item}})
+	}
+	answer := IntentAnswerReq{Text: IntentAnswerText{Content: i.Answer}}
+	for _, attachment := range i.Attachments {
+		item := IntentAnswerAttachmentReq{MsgType: attachment.MsgType}
+		switch attachment.MsgType {
+		case "image":
+			item.Image.MediaID = attachment.MediaID
+		case "video":
+			item.Video.MediaID = attachment.MediaID
+		case "link":
+			item.Link = IntentAnswerAttachmentLink{Title: attachment.Title, PicURL: attachment.PicURL, Desc: attachment.Desc, URL: attachment.URL}
+		case "miniprogram":
+			item.MiniProgram = IntentAnswerAttachmentMiniProgramReq{Title: attachment.Title, ThumbMediaID: attachment.ThumbMediaID, AppID: attachment.AppID, PagePath: attachment.PagePath}
+		}
+		answer.Attachments = append(answer.Attachments, item)
+	}
+	return question, similar, []IntentAnswerReq{answer}
+}
+
+// intentEqual 比较当前问答与目标问答的内容，相似问题不区分顺序
+func intentEqual(current, desired KnowledgeIntentData) bool {
+	if current.Question != desired.Question || current.Answer != desired.Answer {
+		return false
+	}
+	if len(current.SimilarQuestions) != len(desired.SimilarQuestions) || len(current.Attachments) != len(desired.Attachments) {
+		return false
+	}
+	a := append([]string(nil), current.SimilarQuestions...)
+	b := append([]string(nil), desired.SimilarQuestions...)
+	sort.Strings(a)
+	sort.Strings(b)
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	for i := range current.Attachments {
+		if !attachmentEqual(current.Attachments[i], desired.Attachments[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// attachmentEqual 比较附件，问答列表接口不返回media_id，图片与视频只比较文件名，小程序不比较封面
+func attachmentEqual(current, desired KnowledgeAttachment) bool {
+	if current.MsgType != desired.MsgType {
+		return false
+	}
+	switch desired.MsgType {
+	case "image", "video":
+		return desired.Name == "" || current.Name == desired.Name
+	case "link":
+		return current.Title == desired.Title && current.PicURL == desired.PicURL &&
+			current.Desc == desired.Desc && current.URL == desired.URL
+	case "miniprogram":
+		return current.Title == desired.Title && current.AppID == desired.AppID && current.PagePath == desired.PagePath
+	}
+	return true
+}
@@ -0,0 +1,93 @@
+package kf
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/h2non/gock.v1"
+)
+
+func mockKnowledge(path string, body string, reply string) {
+	gock.New("https://qyapi.weixin.qq.com").
+		Post("/cgi-bin/kf/knowledge/" + path).
+		BodyString(body).
+		Reply(200).
+		BodyString(reply)
+}
+
+const knowledgeCSV = `group,intent_id,question,similar_questions,answer,attachments
+售后,,如何退货,"怎么退货
+退货流程",七天内可在订单页申请退货,"[{""msgtype"":""link"",""title"":""退货说明"",""url"":""https://example.com/return""}]"
+售后,,如何换货,,联系客服换货,
+空分组,,,,,
+`
+
+func TestKnowledgeCSV(t *testing.T) {
+	kb, err := ReadKnowledgeCSV(bytes.NewBufferString(knowledgeCSV))
+	assert.Nil(t, err)
+	assert.Len(t, kb.Groups, 2)
+	assert.Equal(t, []string{"怎么退货", "退货流程"}, kb.Groups[0].Intents[0].SimilarQuestions)
+	assert.Equal(t, "https://example.com/return", kb.Groups[0].Intents[0].Attachments[0].URL)
+	assert.Empty(t, kb.Groups[1].Intents)
+
+	var buf bytes.Buffer
+	assert.Nil(t, kb.WriteCSV(&buf))
+	assert.Equal(t, knowledgeCSV, buf.String())
+}
+
+func TestSyncKnowledge(t *testing.T) {
+	defer gock.Off()
+	client := newTestClient(t)
+	desired, err := ReadKnowledgeCSV(bytes.NewBufferString(knowledgeCSV))
+	assert.Nil(t, err)
+
+	// 旧分组中的同名问答不能变更分组，与默认分组中的过期问答一起删除后在新分组中重新添加
+	mockKnowledge("list_group", `"cursor":"","limit":1000`, `{"errcode":0,"has_more":0,"group_list":[
+		{"group_id":"default","name":"默认分组","is_default":1},{"group_id":"old","name":"旧分组","is_default":0}]}`)
+	mockKnowledge("list_intent", `"cursor":"","limit":1000`, `{"errcode":0,"has_more":0,"intent_list":[
+		{"group_id":"default","intent_id":"stale","question":{"text":{"content":"过期问题"}}},
+		{"group_id":"old","intent_id":"keep","question":{"text":{"content":"如何换货"}},"answers":[{"text":{"content":"联系客服换货"}}]}]}`)
+	mockKnowledge("add_group", `"name":"售后"`, `{"errcode":0,"group_id":"G1"}`)
+	mockKnowledge("add_group", `"name":"空分组"`, `{"errcode":0,"group_id":"G2"}`)
+	mockKnowledge("del_intent", `"intent_id":"stale"`, `{"errcode":0}`)
+	mockKnowledge("del_intent", `"intent_id":"keep"`, `{"errcode":0}`)
+	mockKnowledge("add_intent", `"group_id":"G1","question":\{"text":\{"content":"如何退货"\}\}.*"msgtype":"link".*"link":\{"title":"退货说明"`,
+		`{"errcode":0,"intent_id":"I1"}`)
+	mockKnowledge("add_intent", `"group_id":"G1","question":\{"text":\{"content":"如何换货"\}\}`, `{"errcode":0,"intent_id":"I2"}`)
+	mockKnowledge("del_group", `"group_id":"old"`, `{"errcode":0}`)
+	plan, err := client.SyncKnowledge(desired, &KnowledgeDiffOptions{Prune: true})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"售后", "空分组"}, plan.AddGroups)
+	assert.Len(t, plan.AddIntents, 2)
+	assert.Len(t, plan.DelIntents, 2)
+	assert.True(t, gock.IsDone())
+
+	// 问答列表返回的内容与目标一致时不修改，只修改回答变更的问答
+	desired.Groups[0].Intents[1].Answer = "请联系在线客服换货"
+	mockKnowledge("list_group", `"cursor":""`, `{"errcode":0,"has_more":0,"group_list":[
+		{"group_id":"default","name":"默认分组","is_default":1},{"group_id":"G1","name":"售后"},{"group_id":"G2","name":"空分组"}]}`)
+	mockKnowledge("list_intent", `"cursor":""`, `{"errcode":0,"has_more":1,"next_cursor":"next","intent_list":[
+		{"group_id":"G1","intent_id":"I1","question":{"text":{"content":"如何退货"}},
+		"similar_questions":{"items":[{"text":{"content":"退货流程"}},{"text":{"content":"怎么退货"}}]},
+		"answers":[{"text":{"content":"七天内可在订单页申请退货"},"attachments":[{"msgtype":"link","link":{"title":"退货说明","url":"https://example.com/return"}}]}]}]}`)
+	mockKnowledge("list_intent", `"cursor":"next"`, `{"errcode":0,"has_more":0,"intent_list":[
+		{"group_id":"G1","intent_id":"I2","question":{"text":{"content":"如何换货"}},"answers":[{"text":{"content":"联系客服换货"}}]}]}`)
+	mockKnowledge("mod_intent", `"intent_id":"I2".*"请联系在线客服换货"`, `{"errcode":0}`)
+	plan, err = client.SyncKnowledge(desired, nil)
+	assert.Nil(t, err)
+	assert.Len(t, plan.ModIntents, 1)
+	assert.True(t, gock.IsDone())
+}
+
+func TestApplyKnowledgePlanValidate(t *testing.T) {
+	defer gock.Off()
+	client := newTestClient(t)
+	plan := &KnowledgePlan{AddIntents: []KnowledgeIntentChange{{
+		GroupName: "售后",
+		Intent:    KnowledgeIntentData{Question: "图片", Attachments: []KnowledgeAttachment{{MsgType: "image", Name: "a.png"}}},
+	}}}
+	// 图片附件缺少media_id时在调用接口前返回错误
+	assert.NotNil(t, client.ApplyKnowledgePlan(plan))
+	assert.True(t, gock.IsPending())
+}
@@ -0,0 +1,418 @@
+package kf
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/northseadl/wechat/v2/work/kf/syncmsg"
+)
+
+// 发送消息的长度限制，单位字节
+const (
+	maxTextContentBytes  = 2048
+	maxMenuContentBytes  = 1024
+	maxMenuItems         = 10
+	maxMenuClickIDBytes  = 64
+	maxMenuClickBytes    = 128
+	maxMenuTextBytes     = 256
+	maxURLBytes          = 2048
+	maxMenuAppIDBytes    = 32
+	maxMenuPagePathBytes = 1024
+	maxSendMsgIDBytes    = 32
+	maxLocationLatitude  = 90
+	maxLocationLongitude = 180
+)
+
+// 事件响应消息code的有效期
+const (
+	sessionCodeValid = 20 * time.Second
+	poolCodeValid    = 48 * time.Hour
+)
+
+// msgIDPattern 自定义消息ID的取值范围
+var msgIDPattern = regexp.MustCompile(`^[0-9a-zA-Z_-]*$`)
+
+// SendTarget 发送消息的接收方
+type SendTarget struct {
+	ToUser   string // 接收消息的客户UserID
+	OpenKFID string // 发送消息的客服帐号ID
+	MsgID    string // 指定消息ID，可不填，不多于32字节，取值范围：[0-9a-zA-Z_-]*
+}
+
+type (
+	// LinkContent 图文链接消息内容
+	LinkContent struct {
+		Title        string `json:"title"`          // 标题，不超过128个字节，超过会自动截断
+		Desc         string `json:"desc,omitempty"` // 描述，不超过512个字节，超过会自动截断
+		URL          string `json:"url"`            // 点击后跳转的链接，最长2048字节，请确保包含了协议头(http/https)
+		ThumbMediaID string `json:"thumb_media_id"` // 缩略图的media_id，可以通过素材管理接口获得
+	}
+	// MiniProgramContent 小程序消息内容
+	MiniProgramContent struct {
+		AppID        string `json:"appid"`           // 小程序appid，必须是关联到企业的小程序应用
+		Title        string `json:"title,omitempty"` // 小程序消息标题，最多64个字节，超过会自动截断
+		ThumbMediaID string `json:"thumb_media_id"`  // 小程序消息封面的mediaid，封面图建议尺寸为520*416
+		PagePath     string `json:"pagepath"`        // 点击消息卡片后进入的小程序页面路径
+	}
+	// LocationContent 地理位置消息内容
+	LocationContent struct {
+		Name      string  `json:"name,omitempty"`    // 位置名
+		Address   string  `json:"address,omitempty"` // 地址详情说明
+		Latitude  float32 `json:"latitude"`          // 纬度，范围为90 ~ -90
+		Longitude float32 `json:"longitude"`         // 经度，范围为180 ~ -180
+	}
+	// MenuContent 菜单消息内容
+	MenuContent struct {
+		HeadContent string     `json:"head_content,omitempty"` // 起始文本，不多于1024字节
+		List        []MenuItem `json:"list"`                   // 菜单项配置，不能多于10个
+		TailContent string     `json:"tail_content,omitempty"` // 结束文本，不多于1024字节
+	}
+	// MenuItem 菜单项，请使用 NewMenuClick、NewMenuView、NewMenuMiniProgram、NewMenuText 创建
+	MenuItem struct {
+		Type        string               `json:"type"` // 菜单类型：click/view/miniprogram/text
+		Click       *MenuClickItem       `json:"click,omitempty"`
+		View        *MenuViewItem        `json:"view,omitempty"`
+		MiniProgram *MenuMiniProgramItem `json:"miniprogram,omitempty"`
+		Text        *MenuTextItem        `json:"text,omitempty"`
+	}
+	// MenuClickItem 回复菜单
+	MenuClickItem struct {
+		ID      string `json:"id,omitempty"` // 菜单ID，不多于64字节
+		Content string `json:"content"`      // 菜单显示内容，不多于128字节
+	}
+	// MenuViewItem 超链接菜单
+	MenuViewItem struct {
+		URL     string `json:"url"`     // 点击后跳转的链接，不多于2048字节
+		Content string `json:"content"` // 菜单显示内容，不多于1024字节
+	}
+	// MenuMiniProgramItem 小程序菜单
+	MenuMiniProgramItem struct {
+		AppID    string `json:"appid"`    // 小程序appid，不多于32字节
+		PagePath string `json:"pagepath"` // 点击后进入的小程序页面，不多于1024字节
+		Content  string `json:"content"`  // 菜单显示内容，不多于1024字节
+	}
+	// MenuTextItem 文本菜单
+	MenuTextItem struct {
+		Content   string `json:"content"`              // 文本内容，不多于256字节
+		NoNewline int    `json:"no_newline,omitempty"` // 内容后面是否不换行，0-换行 1-不换行
+	}
+)
+
+// NewMenuClick 创建回复菜单，客户点击后会发送一条内容为content的消息，并携带菜单id
+func NewMenuClick(id, content string) MenuItem {
+	return MenuItem{Type: "click", Click: &MenuClickItem{ID: id, Content: content}}
+}
+
+// NewMenuView 创建超链接菜单
+func NewMenuView(url, content string) MenuItem {
+	return MenuItem{Type: "view", View: &MenuViewItem{URL: url, Content: content}}
+}
+
+// NewMenuMiniProgram 创建小程序菜单
+func NewMenuMiniProgram(appID, pagePath, content string) MenuItem {
+	return MenuItem{Type: "miniprogram", MiniProgram: &MenuMiniProgramItem{AppID: appID, PagePath: pagePath, Content: content}}
+}
+
+// NewMenuText 创建文本菜单，noNewline为true时内容后面不换行
+func NewMenuText(content string, noNewline bool) MenuItem {
+	item := &MenuTextItem{Content: content}
+	if noNewline {
+		item.NoNewline = 1
+	}
+	return MenuItem{Type: "text", Text: item}
+}
+
+type (
+	// sendRequest 发送消息与发送事件响应消息的请求
+	sendRequest struct {
+		ToUser      string              `json:"touser,omitempty"`
+		OpenKFID    string              `json:"open_kfid,omitempty"`
+		Code        string              `json:"code,omitempty"`
+		MsgID       string              `json:"msgid,omitempty"`
+		MsgType     string              `json:"msgtype"`
+		Text        *textContent        `json:"text,omitempty"`
+		Image       *mediaContent       `json:"image,omitempty"`
+		Voice       *mediaContent       `json:"voice,omitempty"`
+		Video       *mediaContent       `json:"video,omitempty"`
+		File        *mediaContent       `json:"file,omitempty"`
+		Link        *LinkContent        `json:"link,omitempty"`
+		MiniProgram *MiniProgramContent `json:"miniprogram,omitempty"`
+		MsgMenu     *MenuContent        `json:"msgmenu,omitempty"`
+		Location    *LocationContent    `json:"location,omitempty"`
+		CaLink      *caLinkContent      `json:"ca_link,omitempty"`
+	}
+	textContent struct {
+		Content string `json:"content"`
+	}
+	mediaContent struct {
+		MediaID string `json:"media_id"`
+	}
+	caLinkContent struct {
+		LinkURL string `json:"link_url"`
+	}
+)
+
+// SendText 发送文本消息，content不超过2048字节
+func (r *Client) SendText(target SendTarget, content string) (SendMsgSchema, error) {
+	if err := checkBytes("text.content", content, 1, maxTextContentBytes); err != nil {
+		return SendMsgSchema{}, err
+	}
+	return r.sendTyped(target, &sendRequest{MsgType: "text", Text: &textContent{Content: content}})
+}
+
+// SendImage 发送图片消息
+func (r *Client) SendImage(target SendTarget, mediaID string) (SendMsgSchema, error) {
+	return r.sendMedia(target, "image", mediaID)
+}
+
+// SendVoice 发送语音消息
+func (r *Client) SendVoice(target SendTarget, mediaID string) (SendMsgSchema, error) {
+	return r.sendMedia(target, "voice", mediaID)
+}
+
+// SendVideo 发送视频消息
+func (r *Client) SendVideo(target SendTarget, mediaID string) (SendMsgSchema, error) {
+	return r.sendMedia(target, "video", mediaID)
+}
+
+// SendFile 发送文件消息
+func (r *Client) SendFile(target SendTarget, mediaID string) (SendMsgSchema, error) {
+	return r.sendMedia(target, "file", mediaID)
+}
+
+// SendLink 发送图文链接消息
+func (r *Client) SendLink(target SendTarget, link *LinkContent) (SendMsgSchema, error) {
+	if err := checkBytes("link.url", link.URL, 1, maxURLBytes); err != nil {
+		return SendMsgSchema{}, err
+	}
+	if link.Title == "" || link.ThumbMediaID == "" {
+		return SendMsgSchema{}, fmt.Errorf("link.title and link.thumb_media_id are required")
+	}
+	return r.sendTyped(target, &sendRequest{MsgType: "link", Link: link})
+}
+
+// SendMiniProgram 发送小程序消息
+func (r *Client) SendMiniProgram(target SendTarget, miniProgram *MiniProgramContent) (SendMsgSchema, error) {
+	if miniProgram.AppID == "" || miniProgram.ThumbMediaID == "" || miniProgram.PagePath == "" {
+		return SendMsgSchema{}, fmt.Errorf("miniprogram.appid, miniprogram.thumb_media_id and miniprogram.pagepath are required")
+	}
+	return r.sendTyped(target, &sendRequest{MsgType: "miniprogram", MiniProgram: miniProgram})
+}
+
+// SendMenu 发送菜单消息
+func (r *Client) SendMenu(target SendTarget, menu *MenuContent) (SendMsgSchema, error) {
+	if err := menu.validate(); err != nil {
+		return SendMsgSchema{}, err
+	}
+	return r.sendTyped(target, &sendRequest{MsgType: "msgmenu", MsgMenu: menu})
+}
+
+// SendLocation 发送地理位置消息
+func (r *Client) SendLocation(target SendTarget, location *LocationContent) (SendMsgSchema, error) {
+	if location.Latitude < -maxLocationLatitude || location.Latitude > maxLocationLatitude ||
+		location.Longitude < -maxLocationLongitude || location.Longitude > maxLocationLongitude {
+		return SendMsgSchema{}, fmt.Errorf("location (%v, %v) out of range", location.Latitude, location.Longitude)
+	}
+	return r.sendTyped(target, &sendRequest{MsgType: "location", Location: location})
+}
+
+// SendCaLink 发送获客链接消息
+func (r *Client) SendCaLink(target SendTarget, linkURL string) (SendMsgSchema, error) {
+	if err := checkBytes("ca_link.link_url", linkURL, 1, maxURLBytes); err != nil {
+		return SendMsgSchema{}, err
+	}
+	return r.sendTyped(target, &sendRequest{MsgType: "ca_link", CaLink: &caLinkContent{LinkURL: linkURL}})
+}
+
+// sendMedia 发送图片、语音、视频、文件消息
+func (r *Client) sendMedia(target SendTarget, msgType, mediaID string) (SendMsgSchema, error) {
+	if mediaID == "" {
+		return SendMsgSchema{}, fmt.Errorf("%s.media_id is required", msgType)
+	}
+	content := &mediaContent{MediaID: mediaID}
+	req := &sendRequest{MsgType: msgType}
+	switch msgType {
+	case "image":
+		req.Image = content
+	case "voice":
+		req.Voice = content
+	case "video":
+		req.Video = content
+	case "file":
+		req.File = content
+	}
+	return r.sendTyped(target, req)
+}
+
+// sendTyped 校验接收方后发送消息
+func (r *Client) sendTyped(target SendTarget, req *sendRequest) (SendMsgSchema, error) {
+	if target.ToUser == "" || target.OpenKFID == "" {
+		return SendMsgSchema{}, fmt.Errorf("touser and open_kfid are required")
+	}
+	if err := checkMsgID(target.MsgID); err != nil {
+		return SendMsgSchema{}, err
+	}
+	req.ToUser = target.ToUser
+	req.OpenKFID = target.OpenKFID
+	req.MsgID = target.MsgID
+	return r.SendMsg(req)
+}
+
+// EventScene 事件响应消息的场景
+type EventScene int
+
+const (
+	// EventSceneEnterSession 用户进入会话，用于发送客服欢迎语，code有效期20秒，支持文本、菜单
+	EventSceneEnterSession EventScene = iota + 1
+	// EventSceneEnterPool 进入接待池，用于发送排队提示语等，code有效期48小时，支持文本
+	EventSceneEnterPool
+	// EventSceneFromPool 从接待池接入会话，用于发送非工作时间的提示语或超时未回复的提示语等，code有效期48小时，支持文本
+	EventSceneFromPool
+	// EventSceneEndSession 结束会话，用于发送结束会话提示语或满意度评价等，code有效期20秒，支持文本、菜单
+	EventSceneEndSession
+)
+
+// EventCode 发送事件响应消息的凭证
+type EventCode struct {
+	Code     string     // 事件回调或转接会话接口返回的code
+	Scene    EventScene // 获取code的事件场景，为0时不校验有效期与消息类型
+	IssuedAt time.Time  // 获取code的时间，为零值时不校验有效期
+	MsgID    string     // 指定消息ID，可不填
+}
+
+// WelcomeCode 从用户进入会话事件中获取发送欢迎语的凭证，事件不包含welcome_code时返回false
+func WelcomeCode(event syncmsg.EnterSessionEvent) (EventCode, bool) {
+	code := EventCode{Code: event.Event.WelcomeCode, Scene: EventSceneEnterSession, IssuedAt: sendTime(event.SendTime)}
+	return code, code.Code != ""
+}
+
+// SessionStatusCode 从会话状态变更事件中获取发送回复语或结束语的凭证，事件不包含msg_code时返回false
+func SessionStatusCode(event syncmsg.SessionStatusChangeEvent) (EventCode, bool) {
+	code := EventCode{Code: event.Event.MsgCode, IssuedAt: sendTime(event.SendTime)}
+	switch event.Event.ChangeType {
+	case 1:
+		code.Scene = EventSceneFromPool
+	case 3:
+		code.Scene = EventSceneEndSession
+	}
+	return code, code.Code != ""
+}
+
+// validate 校验code是否仍在有效期内，以及场景是否支持msgType类型的消息
+func (c EventCode) validate(msgType string) error {
+	if c.Code == "" {
+		return fmt.Errorf("event code is required")
+	}
+	if err := checkMsgID(c.MsgID); err != nil {
+		return err
+	}
+	var valid time.Duration
+	switch c.Scene {
+	case EventSceneEnterSession, EventSceneEndSession:
+		valid = sessionCodeValid
+	case EventSceneEnterPool, EventSceneFromPool:
+		valid = poolCodeValid
+		if msgType != "text" {
+			return fmt.Errorf("event scene %d only supports text messages", c.Scene)
+		}
+	default:
+		return nil
+	}
+	if !c.IssuedAt.IsZero() && time.Since(c.IssuedAt) > valid {
+		return fmt.Errorf("event code expired: issued at %s, valid for %s", c.IssuedAt.Format(time.RFC3339), valid)
+	}
+	return nil
+}
+
+// SendTextOnEvent 发送文本事件响应消息，如欢迎语、排队提示语、结束语
+func (r *Client) SendTextOnEvent(code EventCode, content string) (SendMsgOnEventSchema, error) {
+	if err := checkBytes("text.content", content, 1, maxTextContentBytes); err != nil {
+		return SendMsgOnEventSchema{}, err
+	}
+	if err := code.validate("text"); err != nil {
+		return SendMsgOnEventSchema{}, err
+	}
+	return r.SendMsgOnEvent(&sendRequest{Code: code.Code, MsgID: code.MsgID, MsgType: "text", Text: &textContent{Content: content}})
+}
+
+// SendMenuOnEvent 发送菜单事件响应消息，仅用户进入会话与结束会话场景支持
+func (r *Client) SendMenuOnEvent(code EventCode, menu *MenuContent) (SendMsgOnEventSchema, error) {
+	if err := menu.validate(); err != nil {
+		return SendMsgOnEventSchema{}, err
+	}
+	if err := code.validate("msgmenu"); err != nil {
+		return SendMsgOnEventSchema{}, err
+	}
+	return r.SendMsgOnEvent(&sendRequest{Code: code.Code, MsgID: code.MsgID, MsgType: "msgmenu", MsgMenu: menu})
+}
+
+// validate 校验菜单消息的长度限制
+func (m *MenuContent) validate() error {
+	if len(m.List) > maxMenuItems {
+		return fmt.Errorf("msgmenu.list has %d items, at most %d", len(m.List), maxMenuItems)
+	}
+	if err := checkBytes("msgmenu.head_content", m.HeadContent, 0, maxMenuContentBytes); err != nil {
+		return err
+	}
+	if err := checkBytes("msgmenu.tail_content", m.TailContent, 0, maxMenuContentBytes); err != nil {
+		return err
+	}
+	for _, item := range m.List {
+		if err := item.validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validate 校验菜单项的长度限制
+func (i MenuItem) validate() error {
+	switch {
+	case i.Type == "click" && i.Click != nil:
+		if err := checkBytes("click.id", i.Click.ID, 0, maxMenuClickIDBytes); err != nil {
+			return err
+		}
+		return checkBytes("click.content", i.Click.Content, 1, maxMenuClickBytes)
+	case i.Type == "view" && i.View != nil:
+		if err := checkBytes("view.url", i.View.URL, 1, maxURLBytes); err != nil {
+			return err
+		}
+		return checkBytes("view.content", i.View.Content, 1, maxMenuContentBytes)
+	case i.Type == "miniprogram" && i.MiniProgram != nil:
+		if err := checkBytes("miniprogram.appid", i.MiniProgram.AppID, 1, maxMenuAppIDBytes); err != nil {
+			return err
+		}
+		if err := checkBytes("miniprogram.pagepath", i.MiniProgram.PagePath, 1, maxMenuPagePathBytes); err != nil {
+			return err
+		}
+		return checkBytes("miniprogram.content", i.MiniProgram.Content, 1, maxMenuContentBytes)
+	case i.Type == "text" && i.Text != nil:
+		return checkBytes("text.content", i.Text.Content, 1, maxMenuTextBytes)
+	}
+	return fmt.Errorf("invalid msgmenu item of type %q", i.Type)
+}
+
+// checkBytes 校验字段的字节长度在[min, max]范围内
+func checkBytes(field, value string, min, max int) error {
+	if len(value) < min || len(value) > max {
+		return fmt.Errorf("%s must be %d-%d bytes, got %d", field, min, max, len(value))
+	}
+	return nil
+}
+
+// checkMsgID 校验自定义消息ID
+func checkMsgID(msgID string) error {
+	if len(msgID) > maxSendMsgIDBytes || !msgIDPattern.MatchString(msgID) {
+		return fmt.Errorf("invalid msgid %q", msgID)
+	}
+	return nil
+}
+
+// sendTime 将消息的发送时间转换为time.Time，为0时返回零值
+func sendTime(t uint64) time.Time {
+	if t == 0 {
+		return time.Time{}
+	}
+	return time.Unix(int64(t), 0)
+}
@@ -0,0 +1,43 @@
+package kf
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMenuContentValidate(t *testing.T) {
+	menu := &MenuContent{
+		HeadContent: "您对本次服务是否满意呢？",
+		List: []MenuItem{
+			NewMenuClick("101", "满意"),
+			NewMenuView("https://work.weixin.qq.com", "查看详情"),
+			NewMenuMiniProgram("wx123123123123123", "pages/index?userid=zhangsan", "小程序"),
+			NewMenuText("欢迎再次光临", false),
+		},
+	}
+	assert.Nil(t, menu.validate())
+
+	menu.List = append(menu.List, NewMenuClick("102", strings.Repeat("长", 50)))
+	assert.NotNil(t, menu.validate())
+
+	menu.List = []MenuItem{{Type: "click"}}
+	assert.NotNil(t, menu.validate())
+}
+
+func TestEventCodeValidate(t *testing.T) {
+	code := EventCode{Code: "code", Scene: EventSceneEnterSession, IssuedAt: time.Now()}
+	assert.Nil(t, code.validate("msgmenu"))
+
+	code.IssuedAt = time.Now().Add(-time.Minute)
+	assert.NotNil(t, code.validate("text"))
+
+	code = EventCode{Code: "code", Scene: EventSceneEnterPool, IssuedAt: time.Now().Add(-time.Hour)}
+	assert.Nil(t, code.validate("text"))
+	assert.NotNil(t, code.validate("msgmenu"))
+
+	code.MsgID = "invalid msgid"
+	assert.NotNil(t, code.validate("text"))
+}
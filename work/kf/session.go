@@ -0,0 +1,444 @@
+package kf
+
+import (
+	stdcontext "context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/northseadl/wechat/v2/work/kf/syncmsg"
+)
+
+// SessionState 会话状态，与ServiceStateGet返回的service_state一致
+type SessionState int
+
+const (
+	// SessionStateUntreated 未处理，新会话接入
+	SessionStateUntreated SessionState = iota
+	// SessionStateBot 由智能助手接待
+	SessionStateBot
+	// SessionStateQueue 待接入池排队中
+	SessionStateQueue
+	// SessionStateHuman 由人工接待
+	SessionStateHuman
+	// SessionStateEnded 已结束
+	SessionStateEnded
+)
+
+const (
+	// defaultSessionIdleTimeout 默认的会话空闲超时时间
+	defaultSessionIdleTimeout = 30 * time.Minute
+	// originCustomer 微信客户发送的消息
+	originCustomer = 3
+	// originServicer 接待人员在企业微信客户端发送的消息
+	originServicer = 5
+)
+
+// ErrNoReceptionist 没有处于接待中且未达到接待上限的接待人员
+var ErrNoReceptionist = errors.New("no available receptionist")
+
+// Session 客户在客服帐号下的会话
+type Session struct {
+	OpenKFID       string       `json:"open_kfid"`       // 客服帐号ID
+	ExternalUserID string       `json:"external_userid"` // 微信客户的external_userid
+	State          SessionState `json:"state"`           // 会话状态
+	ServicerUserID string       `json:"servicer_userid"` // 接待人员的userid，仅人工接待时有效
+	LastActive     time.Time    `json:"last_active"`     // 最后一条消息的时间，用于判断会话是否空闲
+	UpdatedAt      time.Time    `json:"updated_at"`      // 会话状态的变更时间
+}
+
+// SessionStore 会话存储
+type SessionStore interface {
+	// GetSession 获取会话，不存在时返回nil
+	GetSession(openKFID, externalUserID string) (*Session, error)
+	// SaveSession 保存会话
+	SaveSession(session Session) error
+	// DeleteSession 删除会话
+	DeleteSession(openKFID, externalUserID string) error
+	// RangeSessions 遍历全部会话，fn返回false时停止遍历
+	RangeSessions(fn func(session Session) bool) error
+}
+
+// sessionKey 会话在存储中的key
+type sessionKey struct {
+	openKFID       string
+	externalUserID string
+}
+
+// MemorySessionStore 基于内存的会话存储，仅适用于单实例部署
+type MemorySessionStore struct {
+	mu       sync.RWMutex
+	sessions map[sessionKey]Session
+}
+
+// NewMemorySessionStore 创建基于内存的会话存储
+func NewMemorySessionStore() *MemorySessionStore {
+	return &MemorySessionStore{sessions: make(map[sessionKey]Session)}
+}
+
+// GetSession 获取会话，不存在时返回nil
+func (s *MemorySessionStore) GetSession(openKFID, externalUserID string) (*Session, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	session, ok := s.sessions[sessionKey{openKFID, externalUserID}]
+	if !ok {
+		return nil, nil
+	}
+	return &session, nil
+}
+
+// SaveSession 保存会话
+func (s *MemorySessionStore) SaveSession(session Session) error {
+	s.mu.Lock()
+	s.sessions[sessionKey{session.OpenKFID, session.ExternalUserID}] = session
+	s.mu.Unlock()
+	return nil
+}
+
+// DeleteSession 删除会话
+func (s *MemorySessionStore) DeleteSession(openKFID, externalUserID string) error {
+	s.mu.Lock()
+	delete(s.sessions, sessionKey{openKFID, externalUserID})
+	s.mu.Unlock()
+	return nil
+}
+
+// RangeSessions 遍历全部会话
+func (s *MemorySessionStore) RangeSessions(fn func(session Session) bool) error {
+	s.mu.RLock()
+	sessions := make([]Session, 0, len(s.sessions))
+	for _, session := range s.sessions {
+		sessions = append(sessions, session)
+	}
+	s.mu.RUnlock()
+	for _, session := range sessions {
+		if !fn(session) {
+			break
+		}
+	}
+	return nil
+}
+
+// SessionManagerOptions 会话编排配置
+type SessionManagerOptions struct {
+	Store          SessionStore  // 会话存储，默认使用内存存储
+	AutoBot        bool          // 新会话收到客户消息时是否自动转由智能助手接待
+	MaxLoad        int           // 每个接待人员同时接待的会话上限，为0时不限制
+	IdleTimeout    time.Duration // 会话空闲超时时间，超时后由SweepIdle结束会话，默认30分钟
+	TransferNotice string        // 转人工接待前发送给客户的提示语，为空时不发送
+	QueueNotice    string        // 没有可用接待人员、进入待接入池后发送给客户的提示语，为空时不发送
+	EndNotice      string        // 空闲超时结束会话后发送给客户的结束语，为空时不发送
+	// OnNoticeError 提示语发送失败时回调，会话状态的变更不受影响；为空时忽略
+	OnNoticeError func(session Session, notice string, err error)
+}
+
+// SessionManager 智能助手与人工接待的会话编排，跟踪每个客户的会话状态，
+// 智能助手转人工时分配给当前接待会话最少的接待人员，并结束空闲超时的会话
+type SessionManager struct {
+	client  *Client
+	store   SessionStore
+	options SessionManagerOptions
+	now     func() time.Time
+
+	mu       sync.Mutex // 保护会话记录的读取与保存
+	assignMu sync.Mutex // 串行分配接待人员，避免并发转人工时超过接待上限
+}
+
+// NewSessionManager 创建会话编排
+func NewSessionManager(client *Client, opts *SessionManagerOptions) *SessionManager {
+	var options SessionManagerOptions
+	if opts != nil {
+		options = *opts
+	}
+	if options.Store == nil {
+		options.Store = NewMemorySessionStore()
+	}
+	if options.IdleTimeout <= 0 {
+		options.IdleTimeout = defaultSessionIdleTimeout
+	}
+	return &SessionManager{client: client, store: options.Store, options: options, now: time.Now}
+}
+
+// Register 将会话编排注册到消息消费者，根据拉取到的消息与事件更新会话状态
+func (m *SessionManager) Register(consumer *Consumer) {
+	consumer.HandleAll(m.Track)
+}
+
+// Track 根据客服消息或事件更新会话状态
+func (m *SessionManager) Track(msg syncmsg.Message) error {
+	if msg.MsgType != "event" {
+		return m.trackMessage(msg)
+	}
+	switch msg.EventType {
+	case "enter_session":
+		event, err := msg.GetEnterSessionEvent()
+		if err != nil {
+			return err
+		}
+		return m.update(event.OpenKFID, event.ExternalUserID, func(session *Session) {
+			if session.State == SessionStateEnded {
+				m.setState(session, SessionStateUntreated, "")
+			}
+			session.LastActive = m.activeTime(event.SendTime)
+		})
+	case "session_status_change":
+		event, err := msg.GetSessionStatusChangeEvent()
+		if err != nil {
+			return err
+		}
+		return m.update(event.OpenKFID, event.ExternalUserID, func(session *Session) {
+			switch event.Event.ChangeType {
+			case 1, 2:
+				m.setState(session, SessionStateHuman, event.Event.NewReceptionistUserID)
+			case 3:
+				m.setState(session, SessionStateEnded, "")
+			}
+		})
+	}
+	return nil
+}
+
+// trackMessage 客户或接待人员发送消息时刷新会话的活跃时间，客户发起新会话时按配置转由智能助手接待
+func (m *SessionManager) trackMessage(msg syncmsg.Message) error {
+	if msg.OpenKFID == "" || msg.ExternalUserID == "" {
+		return nil
+	}
+	if msg.Origin != originCustomer && msg.Origin != originServicer {
+		return nil
+	}
+	var toBot bool
+	err := m.update(msg.OpenKFID, msg.ExternalUserID, func(session *Session) {
+		if msg.Origin == originCustomer && session.State == SessionStateEnded {
+			m.setState(session, SessionStateUntreated, "")
+		}
+		session.LastActive = m.activeTime(msg.SendTime)
+		toBot = m.options.AutoBot && session.State == SessionStateUntreated
+	})
+	if err != nil || !toBot {
+		return err
+	}
+	_, err = m.AssignBot(msg.OpenKFID, msg.ExternalUserID)
+	return err
+}
+
+// Session 获取本地记录的会话，不存在时返回nil
+func (m *SessionManager) Session(openKFID, externalUserID string) (*Session, error) {
+	return m.store.GetSession(openKFID, externalUserID)
+}
+
+// Sync 从服务端获取会话状态并更新本地记录
+func (m *SessionManager) Sync(openKFID, externalUserID string) (Session, error) {
+	info, err := m.client.ServiceStateGet(ServiceStateGetOptions{OpenKFID: openKFID, ExternalUserID: externalUserID})
+	if err != nil {
+		return Session{}, err
+	}
+	var result Session
+	err = m.update(openKFID, externalUserID, func(session *Session) {
+		m.setState(session, SessionState(info.ServiceState), info.ServiceUserID)
+		result = *session
+	})
+	return result, err
+}
+
+// AssignBot 将会话转由智能助手接待
+func (m *SessionManager) AssignBot(openKFID, externalUserID string) (Session, error) {
+	return m.transfer(openKFID, externalUserID, SessionStateBot, "", EventCode{}, "")
+}
+
+// Escalate 智能助手转人工，分配给当前接待会话最少的接待人员并发送转接提示语；
+// 没有可用的接待人员时放入待接入池排队并发送排队提示语
+func (m *SessionManager) Escalate(openKFID, externalUserID string) (Session, error) {
+	m.assignMu.Lock()
+	defer m.assignMu.Unlock()
+	servicer, err := m.leastLoaded(openKFID)
+	if errors.Is(err, ErrNoReceptionist) {
+		return m.transfer(openKFID, externalUserID, SessionStateQueue, "",
+			EventCode{Scene: EventSceneEnterPool}, m.options.QueueNotice)
+	}
+	if err != nil {
+		return Session{}, err
+	}
+	return m.transfer(openKFID, externalUserID, SessionStateHuman, servicer, EventCode{}, m.options.TransferNotice)
+}
+
+// TransferTo 将会话转给指定的接待人员
+func (m *SessionManager) TransferTo(openKFID, externalUserID, servicerUserID string) (Session, error) {
+	return m.transfer(openKFID, externalUserID, SessionStateHuman, servicerUserID, EventCode{}, m.options.TransferNotice)
+}
+
+// End 结束会话并发送结束语
+func (m *SessionManager) End(openKFID, externalUserID string) (Session, error) {
+	return m.transfer(openKFID, externalUserID, SessionStateEnded, "",
+		EventCode{Scene: EventSceneEndSession}, m.options.EndNotice)
+}
+
+// SweepIdle 结束空闲超过IdleTimeout的会话，返回结束的会话数，单个会话结束失败不影响其他会话
+func (m *SessionManager) SweepIdle() (int, error) {
+	deadline := m.now().Add(-m.options.IdleTimeout)
+	var idle []Session
+	err := m.store.RangeSessions(func(session Session) bool {
+		if session.State != SessionStateEnded && session.LastActive.Before(deadline) {
+			idle = append(idle, session)
+		}
+		return true
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	var ended int
+	var errs []error
+	for _, session := range idle {
+		if _, err = m.End(session.OpenKFID, session.ExternalUserID); err != nil {
+			errs = append(errs, fmt.Errorf("end session %s/%s: %w", session.OpenKFID, session.ExternalUserID, err))
+			continue
+		}
+		ended++
+	}
+	if len(errs) > 0 {
+		return ended, fmt.Errorf("%d of %d idle sessions failed, first error: %w", len(errs), len(idle), errs[0])
+	}
+	return ended, nil
+}
+
+// RunIdleSweeper 每隔interval调用一次SweepIdle，直到ctx结束，onError为空时忽略错误
+func (m *SessionManager) RunIdleSweeper(ctx stdcontext.Context, interval time.Duration, onError func(error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := m.SweepIdle(); err != nil && onError != nil {
+				onError(err)
+			}
+		}
+	}
+}
+
+// Loads 返回客服帐号下每个接待人员当前人工接待中的会话数
+func (m *SessionManager) Loads(openKFID string) (map[string]int, error) {
+	loads := make(map[string]int)
+	err := m.store.RangeSessions(func(session Session) bool {
+		if session.OpenKFID == openKFID && session.State == SessionStateHuman && session.ServicerUserID != "" {
+			loads[session.ServicerUserID]++
+		}
+		return true
+	})
+	return loads, err
+}
+
+// leastLoaded 选择处于接待中且接待会话最少的接待人员，会话数相同时按userid排序
+func (m *SessionManager) leastLoaded(openKFID string) (string, error) {
+	list, err := m.client.ReceptionistList(openKFID)
+	if err != nil {
+		return "", err
+	}
+	loads, err := m.Loads(openKFID)
+	if err != nil {
+		return "", err
+	}
+	candidates := make([]string, 0, len(list.ReceptionistList))
+	for _, servicer := range list.ReceptionistList {
+		if servicer.Status != 0 {
+			continue
+		}
+		if m.options.MaxLoad > 0 && loads[servicer.UserID] >= m.options.MaxLoad {
+			continue
+		}
+		candidates = append(candidates, servicer.UserID)
+	}
+	if len(candidates) == 0 {
+		return "", ErrNoReceptionist
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if loads[candidates[i]] != loads[candidates[j]] {
+			return loads[candidates[i]] < loads[candidates[j]]
+		}
+		return candidates[i] < candidates[j]
+	})
+	return candidates[0], nil
+}
+
+// transfer 变更会话状态并保存本地记录，提示语发送失败通过OnNoticeError回调，不影响返回结果
+// send_msg仅在未处理与智能助手接待状态下可用，因此转人工的提示语在变更状态前发送；
+// 接口仅在变更为待接入池排队与结束会话时返回msg_code，此时在变更状态后发送事件响应消息
+func (m *SessionManager) transfer(openKFID, externalUserID string, state SessionState, servicerUserID string, code EventCode, notice string) (Session, error) {
+	var noticeErr error
+	if notice != "" && state == SessionStateHuman {
+		if _, err := m.client.SendText(SendTarget{ToUser: externalUserID, OpenKFID: openKFID}, notice); err != nil {
+			noticeErr = fmt.Errorf("send notice: %w", err)
+		}
+	}
+	info, err := m.client.ServiceStateTrans(ServiceStateTransOptions{
+		OpenKFID:       openKFID,
+		ExternalUserID: externalUserID,
+		ServiceState:   int(state),
+		ServicerUserID: servicerUserID,
+	})
+	if err != nil {
+		return Session{}, err
+	}
+	var result Session
+	err = m.update(openKFID, externalUserID, func(session *Session) {
+		m.setState(session, state, servicerUserID)
+		result = *session
+	})
+	if err != nil {
+		return result, err
+	}
+	if notice != "" && state != SessionStateHuman {
+		if info.MsgCode == "" {
+			noticeErr = errors.New("send notice: no msg_code returned")
+		} else {
+			code.Code = info.MsgCode
+			code.IssuedAt = m.now()
+			if _, err = m.client.SendTextOnEvent(code, notice); err != nil {
+				noticeErr = fmt.Errorf("send notice: %w", err)
+			}
+		}
+	}
+	if noticeErr != nil && m.options.OnNoticeError != nil {
+		m.options.OnNoticeError(result, notice, noticeErr)
+	}
+	return result, nil
+}
+
+// update 读取会话并修改后保存，会话不存在时创建未处理状态的会话
+func (m *SessionManager) update(openKFID, externalUserID string, fn func(session *Session)) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	session, err := m.store.GetSession(openKFID, externalUserID)
+	if err != nil {
+		return err
+	}
+	if session == nil {
+		now := m.now()
+		session = &Session{OpenKFID: openKFID, ExternalUserID: externalUserID, LastActive: now, UpdatedAt: now}
+	}
+	fn(session)
+	return m.store.SaveSession(*session)
+}
+
+// setState 修改会话状态，非人工接待状态时清空接待人员
+func (m *SessionManager) setState(session *Session, state SessionState, servicerUserID string) {
+	if state != SessionStateHuman {
+		servicerUserID = ""
+	}
+	if session.State != state || session.ServicerUserID != servicerUserID {
+		session.State = state
+		session.ServicerUserID = servicerUserID
+		session.UpdatedAt = m.now()
+	}
+}
+
+// activeTime 消息的发送时间，消息不包含发送时间时使用当前时间
+func (m *SessionManager) activeTime(t uint64) time.Time {
+	if t == 0 {
+		return m.now()
+	}
+	return sendTime(t)
+}
@@ -0,0 +1,162 @@
+package kf
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/h2non/gock.v1"
+
+	"github.com/northseadl/wechat/v2/work/kf/syncmsg"
+)
+
+func mockServiceStateTrans(body string, msgCode string) {
+	gock.New("https://qyapi.weixin.qq.com").
+		Post("/cgi-bin/kf/service_state/trans").
+		BodyString(body).
+		Reply(200).
+		JSON(map[string]interface{}{"errcode": 0, "msg_code": msgCode})
+}
+
+func mockSendNotice(path string, body string) {
+	gock.New("https://qyapi.weixin.qq.com").
+		Post(path).
+		BodyString(body).
+		Reply(200).
+		JSON(map[string]interface{}{"errcode": 0, "msgid": "notice"})
+}
+
+func customerText(externalUserID string, sendTime time.Time) syncmsg.Message {
+	return syncmsg.Message{
+		MsgID:          externalUserID + sendTime.String(),
+		MsgType:        "text",
+		OpenKFID:       "kf",
+		ExternalUserID: externalUserID,
+		SendTime:       uint64(sendTime.Unix()),
+		Origin:         originCustomer,
+	}
+}
+
+// observeKFCalls 按调用顺序记录客服接口的路径
+func observeKFCalls() *[]string {
+	var calls []string
+	gock.Observe(func(req *http.Request, _ gock.Mock) {
+		if strings.HasPrefix(req.URL.Path, "/cgi-bin/kf/") {
+			calls = append(calls, strings.TrimPrefix(req.URL.Path, "/cgi-bin/kf/"))
+		}
+	})
+	return &calls
+}
+
+func TestSessionManagerEscalate(t *testing.T) {
+	defer gock.Off()
+	defer gock.Observe(nil)
+	manager := NewSessionManager(newTestClient(t), &SessionManagerOptions{AutoBot: true, MaxLoad: 1, TransferNotice: "转人工", QueueNotice: "排队中"})
+	gock.New("https://qyapi.weixin.qq.com").Post("/cgi-bin/kf/service_state/trans").BodyString(`"service_state":1,`).Times(3).
+		Reply(200).JSON(map[string]interface{}{"errcode": 0})
+	gock.New("https://qyapi.weixin.qq.com").Get("/cgi-bin/kf/servicer/list").MatchParam("open_kfid", "kf").Times(3).
+		Reply(200).JSON(map[string]interface{}{"errcode": 0, "servicer_list": []map[string]interface{}{
+		{"userid": "lisi", "status": 0}, {"userid": "zhangsan", "status": 0}, {"userid": "wangwu", "status": 1},
+	}})
+	// send_msg仅在智能助手接待状态下可用，转人工的提示语在变更状态前发送；进入待接入池后使用返回的msg_code发送事件响应消息
+	mockSendNotice("/cgi-bin/kf/send_msg", `"touser":"u1","open_kfid":"kf","msgtype":"text","text":\{"content":"转人工"\}`)
+	mockServiceStateTrans(`"external_userid":"u1","service_state":3,"servicer_userid":"lisi"`, "")
+	mockSendNotice("/cgi-bin/kf/send_msg", `"touser":"u2","open_kfid":"kf","msgtype":"text","text":\{"content":"转人工"\}`)
+	mockServiceStateTrans(`"external_userid":"u2","service_state":3,"servicer_userid":"zhangsan"`, "")
+	mockServiceStateTrans(`"external_userid":"u3","service_state":2,`, "QUEUE_CODE")
+	mockSendNotice("/cgi-bin/kf/send_msg_on_event", `"code":"QUEUE_CODE","msgtype":"text","text":\{"content":"排队中"\}`)
+
+	now := time.Now()
+	for _, user := range []string{"u1", "u2", "u3"} {
+		assert.Nil(t, manager.Track(customerText(user, now)))
+		session, err := manager.Session("kf", user)
+		assert.Nil(t, err)
+		assert.Equal(t, SessionStateBot, session.State)
+	}
+
+	calls := observeKFCalls()
+	first, err := manager.Escalate("kf", "u1")
+	assert.Nil(t, err)
+	assert.Equal(t, SessionStateHuman, first.State)
+	assert.Equal(t, "lisi", first.ServicerUserID)
+
+	second, err := manager.Escalate("kf", "u2")
+	assert.Nil(t, err)
+	assert.Equal(t, "zhangsan", second.ServicerUserID)
+
+	third, err := manager.Escalate("kf", "u3")
+	assert.Nil(t, err)
+	assert.Equal(t, SessionStateQueue, third.State)
+	assert.Equal(t, []string{
+		"servicer/list", "send_msg", "service_state/trans",
+		"servicer/list", "send_msg", "service_state/trans",
+		"servicer/list", "service_state/trans", "send_msg_on_event",
+	}, *calls)
+
+	loads, err := manager.Loads("kf")
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]int{"lisi": 1, "zhangsan": 1}, loads)
+	assert.True(t, gock.IsDone())
+}
+
+func TestSessionManagerNoticeError(t *testing.T) {
+	defer gock.Off()
+	var failed []string
+	manager := NewSessionManager(newTestClient(t), &SessionManagerOptions{
+		TransferNotice: "转人工",
+		EndNotice:      "再见",
+		OnNoticeError: func(session Session, notice string, err error) {
+			failed = append(failed, session.ExternalUserID+":"+notice)
+		},
+	})
+	// 提示语发送失败时会话仍然转接成功
+	gock.New("https://qyapi.weixin.qq.com").Post("/cgi-bin/kf/send_msg").
+		Reply(200).JSON(map[string]interface{}{"errcode": 95018, "errmsg": "send msg not allowed"})
+	mockServiceStateTrans(`"external_userid":"u1","service_state":3,"servicer_userid":"lisi"`, "")
+	// 结束会话未返回msg_code时无法发送结束语
+	mockServiceStateTrans(`"external_userid":"u2","service_state":4,`, "")
+
+	session, err := manager.TransferTo("kf", "u1", "lisi")
+	assert.Nil(t, err)
+	assert.Equal(t, SessionStateHuman, session.State)
+	session, err = manager.End("kf", "u2")
+	assert.Nil(t, err)
+	assert.Equal(t, SessionStateEnded, session.State)
+	assert.Equal(t, []string{"u1:转人工", "u2:再见"}, failed)
+	assert.True(t, gock.IsDone())
+}
+
+func TestSessionManagerTrackAndSweep(t *testing.T) {
+	defer gock.Off()
+	manager := NewSessionManager(newTestClient(t), &SessionManagerOptions{IdleTimeout: time.Minute, EndNotice: "再见"})
+	mockServiceStateTrans(`"open_kfid":"kf","external_userid":"idle","service_state":4,`, "END_CODE")
+	mockSendNotice("/cgi-bin/kf/send_msg_on_event", `"code":"END_CODE","msgtype":"text","text":\{"content":"再见"\}`)
+
+	now := time.Now()
+	assert.Nil(t, manager.Track(customerText("idle", now.Add(-2*time.Minute))))
+	assert.Nil(t, manager.Track(customerText("active", now)))
+
+	event := syncmsg.Message{MsgType: "event", EventType: "session_status_change", OriginData: []byte(
+		`{"msgtype":"event","event":{"event_type":"session_status_change","open_kfid":"kf","external_userid":"active","change_type":1,"new_servicer_userid":"zhangsan"}}`)}
+	assert.Nil(t, manager.Track(event))
+	session, err := manager.Session("kf", "active")
+	assert.Nil(t, err)
+	assert.Equal(t, SessionStateHuman, session.State)
+	assert.Equal(t, "zhangsan", session.ServicerUserID)
+
+	ended, err := manager.SweepIdle()
+	assert.Nil(t, err)
+	assert.Equal(t, 1, ended)
+	assert.True(t, gock.IsDone())
+
+	session, err = manager.Session("kf", "idle")
+	assert.Nil(t, err)
+	assert.Equal(t, SessionStateEnded, session.State)
+
+	assert.Nil(t, manager.Track(customerText("idle", now)))
+	session, err = manager.Session("kf", "idle")
+	assert.Nil(t, err)
+	assert.Equal(t, SessionStateUntreated, session.State)
+}
@@ -5,8 +5,8 @@ import (
 	"errors"
 	"fmt"
 
+	"github.com/northseadl/wechat/v2/work/kf/syncmsg"
 	"github.com/silenceper/wechat/v2/util"
-	"github.com/silenceper/wechat/v2/work/kf/syncmsg"
 )
 
 const (
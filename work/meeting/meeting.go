@@ -0,0 +1,282 @@
+package meeting
+
+import (
+	"fmt"
+
+	"github.com/silenceper/wechat/v2/util"
+)
+
+const (
+	// createURL 创建预约会议
+	createURL = "https://qyapi.weixin.qq.com/cgi-bin/meeting/create?access_token=%s"
+	// updateURL 修改预约会议
+	updateURL = "https://qyapi.weixin.qq.com/cgi-bin/meeting/update?access_token=%s"
+	// cancelURL 取消预约会议
+	cancelURL = "https://qyapi.weixin.qq.com/cgi-bin/meeting/cancel?access_token=%s"
+	// getInfoURL 获取会议详情
+	getInfoURL = "https://qyapi.weixin.qq.com/cgi-bin/meeting/get_info?access_token=%s"
+	// getUserMeetingIDURL 获取成员会议ID列表
+	getUserMeetingIDURL = "https://qyapi.weixin.qq.com/cgi-bin/meeting/get_user_meetingid?access_token=%s"
+)
+
+type (
+	// Attendees 参会人
+	Attendees struct {
+		UserID []string `json:"userid,omitempty"`
+	}
+	// Settings 会议配置
+	Settings struct {
+		RemindScope           int        `json:"remind_scope,omitempty"`            // 会议开始前的提醒范围，1：不提醒 2：仅提醒主持人 3：提醒所有成员入会 4：指定部分人响铃
+		Password              string     `json:"password,omitempty"`                // 入会密码，仅可输入4-6位纯数字
+		EnableWaitingRoom     bool       `json:"enable_waiting_room,omitempty"`     // 是否开启等候室
+		AllowEnterBeforeHost  bool       `json:"allow_enter_before_host,omitempty"` // 是否允许成员在主持人进会前加入
+		EnableEnterMute       int        `json:"enable_enter_mute,omitempty"`       // 成员入会时静音，1：开启；0：关闭；2：超过6人后自动开启静音
+		EnableScreenWatermark bool       `json:"enable_screen_watermark,omitempty"` // 是否开启屏幕水印
+		Hosts                 *Attendees `json:"hosts,omitempty"`                   // 会议主持人列表，仅可设置企业内成员
+		RingUsers             *Attendees `json:"ring_users,omitempty"`              // 指定响铃的成员列表，remind_scope为4时生效
+	}
+	// Reminders 重复会议及提醒配置
+	Reminders struct {
+		IsRepeat       int   `json:"is_repeat,omitempty"`       // 是否是周期性会议，1：周期性会议 0：非周期性会议
+		RepeatType     int   `json:"repeat_type,omitempty"`     // 周期性会议重复类型，0：每天；1：每周；2：每月；7：每个工作日
+		RepeatUntil    int64 `json:"repeat_until,omitempty"`    // 重复结束时刻
+		RepeatInterval int   `json:"repeat_interval,omitempty"` // 重复间隔
+		RemindBefore   []int `json:"remind_before,omitempty"`   // 指定会议开始前多久提醒成员，单位为秒
+	}
+)
+
+type (
+	// CreateRequest 创建预约会议请求
+	CreateRequest struct {
+		AdminUserID     string     `json:"admin_userid"`          // 会议管理员userid
+		Title           string     `json:"title"`                 // 会议的标题，最多支持40个字节或者20个utf8字符
+		MeetingStart    int64      `json:"meeting_start"`         // 会议开始时间的unix时间戳。需大于当前时间
+		MeetingDuration int64      `json:"meeting_duration"`      // 会议持续时间单位秒，最小300秒，最大86399秒
+		Description     string     `json:"description,omitempty"` // 会议的描述，最多支持500个字节或者utf8字符
+		Location        string     `json:"location,omitempty"`    // 会议地点，最多128个字符
+		AgentID         int        `json:"agentid,omitempty"`     // 授权方安装的应用agentid。仅旧的第三方多应用套件需要填此参数
+		Attendees       *Attendees `json:"attendees,omitempty"`   // 参与会议的成员
+		Settings        *Settings  `json:"settings,omitempty"`    // 会议配置
+		CalID           string     `json:"cal_id,omitempty"`      // 会议所属日历ID
+		Reminders       *Reminders `json:"reminders,omitempty"`   // 重复会议相关配置
+	}
+	// CreateResponse 创建预约会议响应
+	CreateResponse struct {
+		util.CommonError
+		MeetingID   string   `json:"meetingid"`
+		ExcessUsers []string `json:"excess_users"` // 参会人中包含无效会议账号的userid，仅在购买会议专业版的企业由于有效账号数不足时返回
+	}
+)
+
+// Create 创建预约会议
+// see https://developer.work.weixin.qq.com/document/path/99104
+func (r *Client) Create(req *CreateRequest) (*CreateResponse, error) {
+	var (
+		accessToken string
+		err         error
+	)
+	if accessToken, err = r.GetAccessToken(); err != nil {
+		return nil, err
+	}
+	var response []byte
+	if response, err = util.PostJSON(fmt.Sprintf(createURL, accessToken), req); err != nil {
+		return nil, err
+	}
+	result := &CreateResponse{}
+	err = util.DecodeWithError(response, result, "CreateMeeting")
+	return result, err
+}
+
+type (
+	// UpdateRequest 修改预约会议请求，仅填写需要修改的字段
+	UpdateRequest struct {
+		MeetingID       string     `json:"meetingid"`
+		Title           string     `json:"title,omitempty"`
+		MeetingStart    int64      `json:"meeting_start,omitempty"`
+		MeetingDuration int64      `json:"meeting_duration,omitempty"`
+		Description     string     `json:"description,omitempty"`
+		Location        string     `json:"location,omitempty"`
+		Attendees       *Attendees `json:"attendees,omitempty"`
+		Settings        *Settings  `json:"settings,omitempty"`
+		CalID           string     `json:"cal_id,omitempty"`
+		Reminders       *Reminders `json:"reminders,omitempty"`
+	}
+	// UpdateResponse 修改预约会议响应
+	UpdateResponse struct {
+		util.CommonError
+		ExcessUsers []string `json:"excess_users"`
+	}
+)
+
+// Update 修改预约会议
+// see https://developer.work.weixin.qq.com/document/path/99047
+func (r *Client) Update(req *UpdateRequest) (*UpdateResponse, error) {
+	var (
+		accessToken string
+		err         error
+	)
+	if accessToken, err = r.GetAccessToken(); err != nil {
+		return nil, err
+	}
+	var response []byte
+	if response, err = util.PostJSON(fmt.Sprintf(updateURL, accessToken), req); err != nil {
+		return nil, err
+	}
+	result := &UpdateResponse{}
+	err = util.DecodeWithError(response, result, "UpdateMeeting")
+	return result, err
+}
+
+// meetingIDRequest 会议ID请求
+type meetingIDRequest struct {
+	MeetingID string `json:"meetingid"`
+}
+
+// Cancel 取消预约会议
+// see https://developer.work.weixin.qq.com/document/path/99048
+func (r *Client) Cancel(meetingID string) error {
+	var (
+		accessToken string
+		err         error
+	)
+	if accessToken, err = r.GetAccessToken(); err != nil {
+		return err
+	}
+	var response []byte
+	if response, err = util.PostJSON(fmt.Sprintf(cancelURL, accessToken), &meetingIDRequest{
+		MeetingID: meetingID,
+	}); err != nil {
+		return err
+	}
+	return util.DecodeWithCommonError(response, "CancelMeeting")
+}
+
+type (
+	// GetInfoResponse 获取会议详情响应
+	GetInfoResponse struct {
+		util.CommonError
+		AdminUserID     string        `json:"admin_userid"`
+		Title           string        `json:"title"`
+		MeetingStart    int64         `json:"meeting_start"`
+		MeetingDuration int64         `json:"meeting_duration"`
+		Description     string        `json:"description"`
+		Location        string        `json:"location"`
+		MainDepartment  int           `json:"main_department"`
+		Status          int           `json:"status"` // 会议状态，1：待开始 2：会议中 3：已结束 4：已取消 5：已过期
+		AgentID         int           `json:"agentid"`
+		Attendees       InfoAttendees `json:"attendees"`
+		Settings        Settings      `json:"settings"`
+		CalID           string        `json:"cal_id"`
+		Reminders       Reminders     `json:"reminders"`
+		MeetingCode     string        `json:"meeting_code"` // 会议号
+		MeetingLink     string        `json:"meeting_link"` // 入会链接
+	}
+	// InfoAttendees 会议详情中的参与人
+	InfoAttendees struct {
+		Member          []AttendeeMember       `json:"member"`
+		TmpExternalUser []AttendeeExternalUser `json:"tmp_external_user"`
+	}
+	// AttendeeMember 企业内参会成员
+	AttendeeMember struct {
+		UserID         string `json:"userid"`
+		Status         int    `json:"status"` // 与会状态，1：已参与 2：未参与
+		FirstJoinTime  int64  `json:"first_join_time"`
+		LastQuitTime   int64  `json:"last_quit_time"`
+		TotalJoinCount int    `json:"total_join_count"`
+		CumulativeTime int64  `json:"cumulative_time"`
+	}
+	// AttendeeExternalUser 会中入会的临时外部参会人
+	AttendeeExternalUser struct {
+		TmpExternalUserID string `json:"tmp_external_userid"`
+		Status            int    `json:"status"`
+		FirstJoinTime     int64  `json:"first_join_time"`
+		LastQuitTime      int64  `json:"last_quit_time"`
+		TotalJoinCount    int    `json:"total_join_count"`
+		CumulativeTime    int64  `json:"cumulative_time"`
+	}
+)
+
+// GetInfo 获取会议详情
+// see https://developer.work.weixin.qq.com/document/path/99049
+func (r *Client) GetInfo(meetingID string) (*GetInfoResponse, error) {
+	var (
+		accessToken string
+		err         error
+	)
+	if accessToken, err = r.GetAccessToken(); err != nil {
+		return nil, err
+	}
+	var response []byte
+	if response, err = util.PostJSON(fmt.Sprintf(getInfoURL, accessToken), &meetingIDRequest{
+		MeetingID: meetingID,
+	}); err != nil {
+		return nil, err
+	}
+	result := &GetInfoResponse{}
+	err = util.DecodeWithError(response, result, "GetMeetingInfo")
+	return result, err
+}
+
+type (
+	// GetUserMeetingIDRequest 获取成员会议ID列表请求
+	GetUserMeetingIDRequest struct {
+		UserID    string `json:"userid"`
+		Cursor    string `json:"cursor,omitempty"`
+		BeginTime int64  `json:"begin_time,omitempty"` // 开始时间，默认为当前时间前180天
+		EndTime   int64  `json:"end_time,omitempty"`   // 结束时间，默认为begin_time后180天
+		Limit     int    `json:"limit,omitempty"`      // 每次拉取的数据量，默认值和最大值都为100
+	}
+	// GetUserMeetingIDResponse 获取成员会议ID列表响应
+	GetUserMeetingIDResponse struct {
+		util.CommonError
+		NextCursor    string   `json:"next_cursor"`
+		MeetingIDList []string `json:"meetingid_list"`
+	}
+)
+
+// GetUserMeetingID 获取成员会议ID列表
+// see https://developer.work.weixin.qq.com/document/path/99050
+func (r *Client) GetUserMeetingID(req *GetUserMeetingIDRequest) (*GetUserMeetingIDResponse, error) {
+	var (
+		accessToken string
+		err         error
+	)
+	if accessToken, err = r.GetAccessToken(); err != nil {
+		return nil, err
+	}
+	var response []byte
+	if response, err = util.PostJSON(fmt.Sprintf(getUserMeetingIDURL, accessToken), req); err != nil {
+		return nil, err
+	}
+	result := &GetUserMeetingIDResponse{}
+	err = util.DecodeWithError(response, result, "GetUserMeetingID")
+	return result, err
+}
+
+// RangeUserMeetingID 从req.Cursor开始逐页获取成员会议ID列表，每获取一页调用一次fn，fn返回错误时停止遍历并返回该错误
+func (r *Client) RangeUserMeetingID(req *GetUserMeetingIDRequest, fn func(meetingIDList []string) error) error {
+	pageReq := *req
+	for {
+		result, err := r.GetUserMeetingID(&pageReq)
+		if err != nil {
+			return err
+		}
+		if err = fn(result.MeetingIDList); err != nil {
+			return err
+		}
+		if result.NextCursor == "" {
+			return nil
+		}
+		pageReq.Cursor = result.NextCursor
+	}
+}
+
+// GetAllUserMeetingID 获取成员在指定时间范围内的全部会议ID
+func (r *Client) GetAllUserMeetingID(req *GetUserMeetingIDRequest) ([]string, error) {
+	var meetingIDList []string
+	err := r.RangeUserMeetingID(req, func(page []string) error {
+		meetingIDList = append(meetingIDList, page...)
+		return nil
+	})
+	return meetingIDList, err
+}
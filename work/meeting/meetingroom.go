@@ -0,0 +1,299 @@
+package meeting
+
+import (
+	"fmt"
+
+	"github.com/silenceper/wechat/v2/util"
+)
+
+const (
+	// addRoomURL 添加会议室
+	addRoomURL = "https://qyapi.weixin.qq.com/cgi-bin/oa/meetingroom/add?access_token=%s"
+	// listRoomURL 查询会议室
+	listRoomURL = "https://qyapi.weixin.qq.com/cgi-bin/oa/meetingroom/list?access_token=%s"
+	// editRoomURL 编辑会议室
+	editRoomURL = "https://qyapi.weixin.qq.com/cgi-bin/oa/meetingroom/edit?access_token=%s"
+	// delRoomURL 删除会议室
+	delRoomURL = "https://qyapi.weixin.qq.com/cgi-bin/oa/meetingroom/del?access_token=%s"
+	// bookRoomURL 预定会议室
+	bookRoomURL = "https://qyapi.weixin.qq.com/cgi-bin/oa/meetingroom/book?access_token=%s"
+	// cancelBookURL 取消预定会议室
+	cancelBookURL = "https://qyapi.weixin.qq.com/cgi-bin/oa/meetingroom/cancel_book?access_token=%s"
+	// getBookingInfoURL 查询会议室的预定信息
+	getBookingInfoURL = "https://qyapi.weixin.qq.com/cgi-bin/oa/meetingroom/get_booking_info?access_token=%s"
+)
+
+// 会议室设备
+const (
+	// EquipmentTV 电视
+	EquipmentTV = 1
+	// EquipmentCamera 电话会议
+	EquipmentCamera = 2
+	// EquipmentProjector 投影
+	EquipmentProjector = 3
+	// EquipmentBoard 白板
+	EquipmentBoard = 4
+	// EquipmentVideo 视频会议
+	EquipmentVideo = 5
+)
+
+type (
+	// RoomCoordinate 会议室所在建筑经纬度
+	RoomCoordinate struct {
+		Latitude  string `json:"latitude"`
+		Longitude string `json:"longitude"`
+	}
+	// RoomRange 会议室的可预定范围
+	RoomRange struct {
+		UserList       []string `json:"user_list,omitempty"`
+		DepartmentList []int    `json:"department_list,omitempty"`
+	}
+	// MeetingRoom 会议室
+	MeetingRoom struct {
+		MeetingRoomID int             `json:"meetingroom_id"`
+		Name          string          `json:"name"`
+		Capacity      int             `json:"capacity"`
+		City          string          `json:"city"`
+		Building      string          `json:"building"`
+		Floor         string          `json:"floor"`
+		Equipment     []int           `json:"equipment"`
+		Coordinate    *RoomCoordinate `json:"coordinate"`
+		NeedApproval  int             `json:"need_approval"` // 是否需要审批，0-无需审批，1-需要审批
+	}
+)
+
+type (
+	// AddRoomRequest 添加会议室请求
+	AddRoomRequest struct {
+		Name       string          `json:"name"`
+		Capacity   int             `json:"capacity"`
+		City       string          `json:"city,omitempty"`
+		Building   string          `json:"building,omitempty"`
+		Floor      string          `json:"floor,omitempty"`
+		Equipment  []int           `json:"equipment,omitempty"`
+		Coordinate *RoomCoordinate `json:"coordinate,omitempty"`
+		Range      *RoomRange      `json:"range,omitempty"`
+	}
+	// AddRoomResponse 添加会议室响应
+	AddRoomResponse struct {
+		util.CommonError
+		MeetingRoomID int `json:"meetingroom_id"`
+	}
+)
+
+// AddRoom 添加会议室
+// see https://developer.work.weixin.qq.com/document/path/93619
+func (r *Client) AddRoom(req *AddRoomRequest) (*AddRoomResponse, error) {
+	var (
+		accessToken string
+		err         error
+	)
+	if accessToken, err = r.GetAccessToken(); err != nil {
+		return nil, err
+	}
+	var response []byte
+	if response, err = util.PostJSON(fmt.Sprintf(addRoomURL, accessToken), req); err != nil {
+		return nil, err
+	}
+	result := &AddRoomResponse{}
+	err = util.DecodeWithError(response, result, "AddMeetingRoom")
+	return result, err
+}
+
+type (
+	// ListRoomRequest 查询会议室请求，不填写条件时返回全部会议室
+	ListRoomRequest struct {
+		City      string `json:"city,omitempty"`
+		Building  string `json:"building,omitempty"`
+		Floor     string `json:"floor,omitempty"`
+		Equipment []int  `json:"equipment,omitempty"`
+	}
+	// ListRoomResponse 查询会议室响应
+	ListRoomResponse struct {
+		util.CommonError
+		MeetingRoomList []MeetingRoom `json:"meetingroom_list"`
+	}
+)
+
+// ListRoom 查询会议室
+// see https://developer.work.weixin.qq.com/document/path/93619
+func (r *Client) ListRoom(req *ListRoomRequest) ([]MeetingRoom, error) {
+	var (
+		accessToken string
+		err         error
+	)
+	if accessToken, err = r.GetAccessToken(); err != nil {
+		return nil, err
+	}
+	var response []byte
+	if response, err = util.PostJSON(fmt.Sprintf(listRoomURL, accessToken), req); err != nil {
+		return nil, err
+	}
+	result := &ListRoomResponse{}
+	err = util.DecodeWithError(response, result, "ListMeetingRoom")
+	return result.MeetingRoomList, err
+}
+
+// EditRoomRequest 编辑会议室请求
+type EditRoomRequest struct {
+	MeetingRoomID int             `json:"meetingroom_id"`
+	Name          string          `json:"name,omitempty"`
+	Capacity      int             `json:"capacity,omitempty"`
+	City          string          `json:"city,omitempty"`
+	Building      string          `json:"building,omitempty"`
+	Floor         string          `json:"floor,omitempty"`
+	Equipment     []int           `json:"equipment,omitempty"`
+	Coordinate    *RoomCoordinate `json:"coordinate,omitempty"`
+	Range         *RoomRange      `json:"range,omitempty"`
+}
+
+// EditRoom 编辑会议室
+// see https://developer.work.weixin.qq.com/document/path/93619
+func (r *Client) EditRoom(req *EditRoomRequest) error {
+	var (
+		accessToken string
+		err         error
+	)
+	if accessToken, err = r.GetAccessToken(); err != nil {
+		return err
+	}
+	var response []byte
+	if response, err = util.PostJSON(fmt.Sprintf(editRoomURL, accessToken), req); err != nil {
+		return err
+	}
+	return util.DecodeWithCommonError(response, "EditMeetingRoom")
+}
+
+// roomIDRequest 会议室ID请求
+type roomIDRequest struct {
+	MeetingRoomID int `json:"meetingroom_id"`
+}
+
+// DelRoom 删除会议室
+// see https://developer.work.weixin.qq.com/document/path/93619
+func (r *Client) DelRoom(meetingRoomID int) error {
+	var (
+		accessToken string
+		err         error
+	)
+	if accessToken, err = r.GetAccessToken(); err != nil {
+		return err
+	}
+	var response []byte
+	if response, err = util.PostJSON(fmt.Sprintf(delRoomURL, accessToken), &roomIDRequest{
+		MeetingRoomID: meetingRoomID,
+	}); err != nil {
+		return err
+	}
+	return util.DecodeWithCommonError(response, "DelMeetingRoom")
+}
+
+type (
+	// BookRoomRequest 预定会议室请求
+	BookRoomRequest struct {
+		MeetingRoomID int      `json:"meetingroom_id"`
+		Subject       string   `json:"subject,omitempty"`
+		StartTime     int64    `json:"start_time"` // 预定开始时间，必须为整点或者半点
+		EndTime       int64    `json:"end_time"`   // 预定结束时间，必须为整点或者半点
+		Booker        string   `json:"booker"`     // 预定人的userid
+		Attendees     []string `json:"attendees,omitempty"`
+	}
+	// BookRoomResponse 预定会议室响应
+	BookRoomResponse struct {
+		util.CommonError
+		BookingID  string `json:"booking_id"`
+		ScheduleID string `json:"schedule_id"`
+	}
+)
+
+// BookRoom 预定会议室
+// see https://developer.work.weixin.qq.com/document/path/93620
+func (r *Client) BookRoom(req *BookRoomRequest) (*BookRoomResponse, error) {
+	var (
+		accessToken string
+		err         error
+	)
+	if accessToken, err = r.GetAccessToken(); err != nil {
+		return nil, err
+	}
+	var response []byte
+	if response, err = util.PostJSON(fmt.Sprintf(bookRoomURL, accessToken), req); err != nil {
+		return nil, err
+	}
+	result := &BookRoomResponse{}
+	err = util.DecodeWithError(response, result, "BookMeetingRoom")
+	return result, err
+}
+
+// CancelBookRequest 取消预定会议室请求
+type CancelBookRequest struct {
+	BookingID    string `json:"booking_id"`
+	KeepSchedule int    `json:"keep_schedule,omitempty"` // 是否保留日程，0-同步删除 1-保留
+}
+
+// CancelBook 取消预定会议室
+// see https://developer.work.weixin.qq.com/document/path/93620
+func (r *Client) CancelBook(req *CancelBookRequest) error {
+	var (
+		accessToken string
+		err         error
+	)
+	if accessToken, err = r.GetAccessToken(); err != nil {
+		return err
+	}
+	var response []byte
+	if response, err = util.PostJSON(fmt.Sprintf(cancelBookURL, accessToken), req); err != nil {
+		return err
+	}
+	return util.DecodeWithCommonError(response, "CancelBookMeetingRoom")
+}
+
+type (
+	// GetBookingInfoRequest 查询会议室的预定信息请求
+	GetBookingInfoRequest struct {
+		MeetingRoomID int    `json:"meetingroom_id,omitempty"`
+		StartTime     int64  `json:"start_time,omitempty"` // 查询预定的起始时间，默认为当前时间
+		EndTime       int64  `json:"end_time,omitempty"`   // 查询预定的结束时间，默认为明日0时
+		City          string `json:"city,omitempty"`
+		Building      string `json:"building,omitempty"`
+		Floor         string `json:"floor,omitempty"`
+	}
+	// GetBookingInfoResponse 查询会议室的预定信息响应
+	GetBookingInfoResponse struct {
+		util.CommonError
+		BookingList []RoomBooking `json:"booking_list"`
+	}
+	// RoomBooking 会议室的预定信息
+	RoomBooking struct {
+		MeetingRoomID int               `json:"meetingroom_id"`
+		Schedule      []BookingSchedule `json:"schedule"`
+	}
+	// BookingSchedule 会议室的预定日程
+	BookingSchedule struct {
+		BookingID  string `json:"booking_id"`
+		ScheduleID string `json:"schedule_id"`
+		StartTime  int64  `json:"start_time"`
+		EndTime    int64  `json:"end_time"`
+		Booker     string `json:"booker"`
+		Status     int    `json:"status"` // 会议室的预定状态，0：已预定 、2：申请中、3：审批中
+	}
+)
+
+// GetBookingInfo 查询会议室的预定信息
+// see https://developer.work.weixin.qq.com/document/path/93620
+func (r *Client) GetBookingInfo(req *GetBookingInfoRequest) ([]RoomBooking, error) {
+	var (
+		accessToken string
+		err         error
+	)
+	if accessToken, err = r.GetAccessToken(); err != nil {
+		return nil, err
+	}
+	var response []byte
+	if response, err = util.PostJSON(fmt.Sprintf(getBookingInfoURL, accessToken), req); err != nil {
+		return nil, err
+	}
+	result := &GetBookingInfoResponse{}
+	err = util.DecodeWithError(response, result, "GetMeetingRoomBookingInfo")
+	return result.BookingList, err
+}
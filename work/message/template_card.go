@@ -0,0 +1,258 @@
+package message
+
+import (
+	"fmt"
+
+	"github.com/silenceper/wechat/v2/util"
+)
+
+const (
+	// 更新模版卡片消息的接口地址
+	updateTemplateCardURL = "https://qyapi.weixin.qq.com/cgi-bin/message/update_template_card?access_token=%s"
+)
+
+// CardType 模板卡片类型
+type CardType string
+
+const (
+	// CardTypeTextNotice 文本通知型
+	CardTypeTextNotice CardType = "text_notice"
+	// CardTypeNewsNotice 图文展示型
+	CardTypeNewsNotice CardType = "news_notice"
+	// CardTypeButtonInteraction 按钮交互型
+	CardTypeButtonInteraction CardType = "button_interaction"
+	// CardTypeVoteInteraction 投票选择型
+	CardTypeVoteInteraction CardType = "vote_interaction"
+	// CardTypeMultipleInteraction 多项选择型
+	CardTypeMultipleInteraction CardType = "multiple_interaction"
+)
+
+type (
+	// TemplateCard 模板卡片，不同类型的卡片使用的字段不同，建议通过 NewTextNoticeCard 等方法构造后再补充可选字段
+	TemplateCard struct {
+		CardType              CardType            `json:"card_type"`
+		Source                *CardSource         `json:"source,omitempty"`
+		ActionMenu            *CardActionMenu     `json:"action_menu,omitempty"` // 卡片右上角更多操作按钮，设置时必须同时设置TaskID
+		TaskID                string              `json:"task_id,omitempty"`     // 任务id，同一个应用任务id不能重复，只能由数字、字母和“_-@”组成，最长128字节
+		MainTitle             *CardTitle          `json:"main_title,omitempty"`
+		QuoteArea             *CardQuoteArea      `json:"quote_area,omitempty"`
+		EmphasisContent       *CardTitle          `json:"emphasis_content,omitempty"` // 关键数据样式，仅text_notice
+		SubTitleText          string              `json:"sub_title_text,omitempty"`
+		HorizontalContentList []CardHorizontal    `json:"horizontal_content_list,omitempty"`
+		JumpList              []CardJump          `json:"jump_list,omitempty"`
+		CardAction            *CardAction         `json:"card_action,omitempty"`
+		CardImage             *CardImage          `json:"card_image,omitempty"`            // 图片样式，仅news_notice
+		ImageTextArea         *CardImageTextArea  `json:"image_text_area,omitempty"`       // 左图右文样式，仅news_notice
+		VerticalContentList   []CardTitle         `json:"vertical_content_list,omitempty"` // 卡片二级垂直内容，仅news_notice
+		ButtonSelection       *CardSelect         `json:"button_selection,omitempty"`      // 下拉式的选择器，仅button_interaction
+		ButtonList            []CardButton        `json:"button_list,omitempty"`           // 按钮列表，仅button_interaction
+		Checkbox              *CardCheckbox       `json:"checkbox,omitempty"`              // 选择题样式，仅vote_interaction
+		SelectList            []CardSelect        `json:"select_list,omitempty"`           // 下拉式的选择器列表，仅multiple_interaction
+		SubmitButton          *CardSubmitButton   `json:"submit_button,omitempty"`         // 提交按钮样式，仅vote_interaction与multiple_interaction
+		ReplaceText           string              `json:"replace_text,omitempty"`          // 更新卡片时，按钮替换文案
+		Feedback              *CardActionFeedback `json:"feedback,omitempty"`              // 更新卡片时的反馈信息
+	}
+	// CardSource 卡片来源样式信息
+	CardSource struct {
+		IconURL   string `json:"icon_url,omitempty"`
+		Desc      string `json:"desc,omitempty"`
+		DescColor int    `json:"desc_color,omitempty"` // 来源文字的颜色，0(默认) 灰色，1 黑色，2 红色，3 绿色
+	}
+	// CardActionMenu 卡片右上角更多操作按钮
+	CardActionMenu struct {
+		Desc       string           `json:"desc,omitempty"`
+		ActionList []CardActionItem `json:"action_list"` // 操作列表，列表长度取值范围为 [1, 3]
+	}
+	// CardActionItem 更多操作按钮中的操作
+	CardActionItem struct {
+		Text string `json:"text"`
+		Key  string `json:"key"`
+	}
+	// CardTitle 标题与描述
+	CardTitle struct {
+		Title string `json:"title,omitempty"`
+		Desc  string `json:"desc,omitempty"`
+	}
+	// CardQuoteArea 引用文献样式
+	CardQuoteArea struct {
+		Type      int    `json:"type,omitempty"` // 引用文献样式区域点击事件，0或不填代表没有点击事件，1 代表跳转url，2 代表跳转小程序
+		URL       string `json:"url,omitempty"`
+		AppID     string `json:"appid,omitempty"`
+		PagePath  string `json:"pagepath,omitempty"`
+		Title     string `json:"title,omitempty"`
+		QuoteText string `json:"quote_text,omitempty"`
+	}
+	// CardHorizontal 二级标题+文本列表
+	CardHorizontal struct {
+		Type    int    `json:"type,omitempty"` // 链接类型，0或不填代表不是链接，1 代表跳转url，2 代表下载附件，3 代表点击跳转成员详情
+		KeyName string `json:"keyname"`
+		Value   string `json:"value,omitempty"`
+		URL     string `json:"url,omitempty"`
+		MediaID string `json:"media_id,omitempty"`
+		UserID  string `json:"userid,omitempty"`
+	}
+	// CardJump 跳转指引样式
+	CardJump struct {
+		Type     int    `json:"type,omitempty"` // 跳转链接类型，0或不填代表不是链接，1 代表跳转url，2 代表跳转小程序
+		Title    string `json:"title"`
+		URL      string `json:"url,omitempty"`
+		AppID    string `json:"appid,omitempty"`
+		PagePath string `json:"pagepath,omitempty"`
+	}
+	// CardAction 整体卡片的点击跳转事件
+	CardAction struct {
+		Type     int    `json:"type"` // 跳转事件类型，0或不填代表不是链接，1 代表跳转url，2 代表打开小程序
+		URL      string `json:"url,omitempty"`
+		AppID    string `json:"appid,omitempty"`
+		PagePath string `json:"pagepath,omitempty"`
+	}
+	// CardImage 图片样式
+	CardImage struct {
+		URL         string  `json:"url"`
+		AspectRatio float64 `json:"aspect_ratio,omitempty"` // 图片的宽高比，宽高比要小于2.25，大于1.3，不填该参数默认1.3
+	}
+	// CardImageTextArea 左图右文样式
+	CardImageTextArea struct {
+		Type     int    `json:"type,omitempty"`
+		URL      string `json:"url,omitempty"`
+		AppID    string `json:"appid,omitempty"`
+		PagePath string `json:"pagepath,omitempty"`
+		Title    string `json:"title,omitempty"`
+		Desc     string `json:"desc,omitempty"`
+		ImageURL string `json:"image_url"`
+	}
+	// CardSelect 下拉式的选择器
+	CardSelect struct {
+		QuestionKey string       `json:"question_key"`
+		Title       string       `json:"title,omitempty"`
+		Disable     bool         `json:"disable,omitempty"` // 更新卡片时，下拉框是否不可选
+		SelectedID  string       `json:"selected_id,omitempty"`
+		OptionList  []CardOption `json:"option_list"`
+	}
+	// CardOption 选项
+	CardOption struct {
+		ID        string `json:"id"`
+		Text      string `json:"text"`
+		IsChecked bool   `json:"is_checked,omitempty"` // 仅vote_interaction的checkbox使用
+	}
+	// CardButton 按钮
+	CardButton struct {
+		Type  int    `json:"type,omitempty"` // 按钮点击事件类型，0 或不填代表回调点击事件，1 代表跳转url
+		Text  string `json:"text"`
+		Style int    `json:"style,omitempty"` // 按钮样式，目前可填1~4，不填或错填默认1
+		Key   string `json:"key,omitempty"`
+		URL   string `json:"url,omitempty"`
+	}
+	// CardCheckbox 选择题样式
+	CardCheckbox struct {
+		QuestionKey string       `json:"question_key"`
+		OptionList  []CardOption `json:"option_list"`
+		Disable     bool         `json:"disable,omitempty"`
+		Mode        int          `json:"mode,omitempty"` // 选择题模式，单选：0，多选：1，不填默认0
+	}
+	// CardSubmitButton 提交按钮样式
+	CardSubmitButton struct {
+		Text string `json:"text"`
+		Key  string `json:"key"`
+	}
+	// CardActionFeedback 更新卡片时的反馈信息
+	CardActionFeedback struct {
+		Title string `json:"title"`
+	}
+)
+
+// NewTextNoticeCard 文本通知型卡片，mainTitle与subTitleText至少填写一项，action为整体卡片的点击跳转事件
+func NewTextNoticeCard(mainTitle *CardTitle, subTitleText string, action *CardAction) *TemplateCard {
+	return &TemplateCard{
+		CardType:     CardTypeTextNotice,
+		MainTitle:    mainTitle,
+		SubTitleText: subTitleText,
+		CardAction:   action,
+	}
+}
+
+// NewNewsNoticeCard 图文展示型卡片，image与imageTextArea至少填写一项
+func NewNewsNoticeCard(mainTitle *CardTitle, image *CardImage, imageTextArea *CardImageTextArea, action *CardAction) *TemplateCard {
+	return &TemplateCard{
+		CardType:      CardTypeNewsNotice,
+		MainTitle:     mainTitle,
+		CardImage:     image,
+		ImageTextArea: imageTextArea,
+		CardAction:    action,
+	}
+}
+
+// NewButtonInteractionCard 按钮交互型卡片，taskID用于接收回调以及更新卡片，按钮列表长度不超过6
+func NewButtonInteractionCard(taskID string, mainTitle *CardTitle, buttons ...CardButton) *TemplateCard {
+	return &TemplateCard{
+		CardType:   CardTypeButtonInteraction,
+		TaskID:     taskID,
+		MainTitle:  mainTitle,
+		ButtonList: buttons,
+	}
+}
+
+// NewVoteInteractionCard 投票选择型卡片，选项数量不超过20
+func NewVoteInteractionCard(taskID string, mainTitle *CardTitle, checkbox *CardCheckbox, submit *CardSubmitButton) *TemplateCard {
+	return &TemplateCard{
+		CardType:     CardTypeVoteInteraction,
+		TaskID:       taskID,
+		MainTitle:    mainTitle,
+		Checkbox:     checkbox,
+		SubmitButton: submit,
+	}
+}
+
+// NewMultipleInteractionCard 多项选择型卡片，选择器数量不超过3
+func NewMultipleInteractionCard(taskID string, mainTitle *CardTitle, submit *CardSubmitButton, selects ...CardSelect) *TemplateCard {
+	return &TemplateCard{
+		CardType:     CardTypeMultipleInteraction,
+		TaskID:       taskID,
+		MainTitle:    mainTitle,
+		SelectList:   selects,
+		SubmitButton: submit,
+	}
+}
+
+type (
+	// UpdateTemplateCardRequest 更新模版卡片消息请求，Button与TemplateCard二选一
+	UpdateTemplateCardRequest struct {
+		UserIDs       []string            `json:"userids,omitempty"`  // 企业的成员ID列表（最多支持1000个）
+		PartyIDs      []int               `json:"partyids,omitempty"` // 企业的部门ID列表（最多支持100个）
+		TagIDs        []int               `json:"tagids,omitempty"`   // 企业的标签ID列表（最多支持100个）
+		AtAll         int                 `json:"atall,omitempty"`    // 更新整个任务接收人员
+		AgentID       int                 `json:"agentid"`
+		ResponseCode  string              `json:"response_code"` // 发送消息或用户点击卡片回调时返回的response_code，72小时内有效，且只能使用一次
+		EnableIDTrans int                 `json:"enable_id_trans,omitempty"`
+		Button        *UpdateButtonStatus `json:"button,omitempty"`        // 仅更新按钮为不可点击状态
+		TemplateCard  *TemplateCard       `json:"template_card,omitempty"` // 更新为新的卡片
+	}
+	// UpdateButtonStatus 按钮更新后的状态
+	UpdateButtonStatus struct {
+		ReplaceName string `json:"replace_name"` // 需要更新的按钮的文案
+	}
+	// UpdateTemplateCardResponse 更新模版卡片消息响应
+	UpdateTemplateCardResponse struct {
+		util.CommonError
+		InvalidUser []string `json:"invaliduser"`
+	}
+)
+
+// UpdateTemplateCard 更新模版卡片消息，用于用户点击交互类卡片后更新按钮状态或卡片内容
+// @see https://developer.work.weixin.qq.com/document/path/94888
+func (r *Client) UpdateTemplateCard(req *UpdateTemplateCardRequest) (*UpdateTemplateCardResponse, error) {
+	var (
+		accessToken string
+		err         error
+	)
+	if accessToken, err = r.GetAccessToken(); err != nil {
+		return nil, err
+	}
+	var response []byte
+	if response, err = util.PostJSON(fmt.Sprintf(updateTemplateCardURL, accessToken), req); err != nil {
+		return nil, err
+	}
+	result := &UpdateTemplateCardResponse{}
+	err = util.DecodeWithError(response, result, "UpdateTemplateCard")
+	return result, err
+}
@@ -29,9 +29,39 @@ type ChatMessage struct {
 	ToList     []string // 消息接收方列表，可能是多个，同一个企业内容为userid，非相同企业为external_userid。
 	Action     string   // 消息动作，目前有send(发送消息)/recall(撤回消息)/switch(切换企业日志)三种类型。
 	Type       string   // 消息类型
+	RoomID     string   // 群聊消息的群id。如果是单聊则为空。
+	MsgTime    int64    // 消息发送时间戳，utc时间，ms单位。
 	originData []byte   // 原始消息对象
 }
 
+// parseChatMessage 解析解密后的消息明文
+func parseChatMessage(buf []byte) (msg ChatMessage, err error) {
+	// handle illegal escape character in text
+	for i := 0; i < len(buf); {
+		if buf[i] < 0x20 {
+			buf = append(buf[:i], buf[i+1:]...)
+			continue
+		}
+		i++
+	}
+
+	var baseMessage BaseMessage
+	err = json.Unmarshal(buf, &baseMessage)
+	if err != nil {
+		return msg, err
+	}
+
+	msg.ID = baseMessage.MsgID
+	msg.From = baseMessage.From
+	msg.ToList = baseMessage.ToList
+	msg.Action = baseMessage.Action
+	msg.Type = baseMessage.MsgType
+	msg.RoomID = baseMessage.RoomID
+	msg.MsgTime = baseMessage.MsgTime
+	msg.originData = buf
+	return msg, err
+}
+
 // GetOriginMessage 获取消息原始数据
 func (c ChatMessage) GetOriginMessage() (msg map[string]interface{}, err error) {
 	err = json.Unmarshal(c.originData, &msg)
@@ -10,17 +10,18 @@ package msgaudit
 // #include "WeWorkFinanceSdk_C.h"
 import "C"
 import (
+	stdcontext "context"
 	"encoding/json"
+	"io"
 	"unsafe"
 
-	"github.com/silenceper/wechat/v2/util"
 	"github.com/silenceper/wechat/v2/work/config"
 )
 
 // Client 会话存档
 type Client struct {
-	ptr        *C.WeWorkFinanceSdk_t
-	privateKey string
+	ptr     *C.WeWorkFinanceSdk_t
+	keyRing *KeyRing
 }
 
 // NewClient 初始会话会话存档实例
@@ -39,6 +40,12 @@ type Client struct {
 *      !=0 - 失败
  */
 func NewClient(cfg *config.Config) (*Client, error) {
+	keyRing := NewKeyRing()
+	if cfg.RasPrivateKey != "" {
+		if err := keyRing.SetDefault(cfg.RasPrivateKey); err != nil {
+			return nil, err
+		}
+	}
 	ptr := C.NewSdk()
 	corpIDC := C.CString(cfg.CorpID)
 	corpSecretC := C.CString(cfg.CorpSecret)
@@ -52,11 +59,16 @@ func NewClient(cfg *config.Config) (*Client, error) {
 		return nil, NewSDKErr(ret)
 	}
 	return &Client{
-		ptr:        ptr,
-		privateKey: cfg.RasPrivateKey,
+		ptr:     ptr,
+		keyRing: keyRing,
 	}, nil
 }
 
+// KeyRing 返回消息加密私钥环，Config.RasPrivateKey 作为默认私钥，轮换公钥后可通过 KeyRing().Add 添加各版本私钥
+func (s *Client) KeyRing() *KeyRing {
+	return s.keyRing
+}
+
 // Free 释放SDK实例是可调用该方法释放内存
 func (s *Client) Free() {
 	if s.ptr == nil {
@@ -162,11 +174,26 @@ func (s *Client) GetRawChatData(seq uint64, limit uint64, proxy string, passwd s
 *      0   - 成功
 *      !=0 - 失败
  */
+// 未指定公钥版本，依次尝试默认私钥与私钥环中的各版本私钥，已知版本时请使用 DecryptChatData
 func (s *Client) DecryptData(encryptRandomKey string, encryptMsg string) (msg ChatMessage, err error) {
-	encryptKey, err := util.RSADecryptBase64(s.privateKey, encryptRandomKey)
+	encryptKey, err := s.keyRing.TryDecryptRandomKey(encryptRandomKey)
 	if err != nil {
 		return msg, err
 	}
+	return s.decryptMessage(encryptKey, encryptMsg)
+}
+
+// DecryptChatData 使用 ChatData.PublickeyVer 对应版本的私钥解密消息
+func (s *Client) DecryptChatData(data ChatData) (msg ChatMessage, err error) {
+	encryptKey, err := s.keyRing.DecryptRandomKey(data.PublickeyVer, data.EncryptRandomKey)
+	if err != nil {
+		return msg, err
+	}
+	return s.decryptMessage(encryptKey, data.EncryptChatMsg)
+}
+
+// decryptMessage 使用解密后的encrypt_random_key调用SDK解密消息
+func (s *Client) decryptMessage(encryptKey []byte, encryptMsg string) (ChatMessage, error) {
 	encryptKeyC := C.CString(string(encryptKey))
 	encryptMsgC := C.CString(encryptMsg)
 	msgSlice := C.NewSlice()
@@ -179,32 +206,9 @@ func (s *Client) DecryptData(encryptRandomKey string, encryptMsg string) (msg Ch
 	retC := C.DecryptData(encryptKeyC, encryptMsgC, msgSlice)
 	ret := int(retC)
 	if ret != 0 {
-		return msg, NewSDKErr(ret)
+		return ChatMessage{}, NewSDKErr(ret)
 	}
-	buf := s.GetContentFromSlice(msgSlice)
-
-	// handle illegal escape character in text
-	for i := 0; i < len(buf); {
-		if buf[i] < 0x20 {
-			buf = append(buf[:i], buf[i+1:]...)
-			continue
-		}
-		i++
-	}
-
-	var baseMessage BaseMessage
-	err = json.Unmarshal(buf, &baseMessage)
-	if err != nil {
-		return msg, err
-	}
-
-	msg.ID = baseMessage.MsgID
-	msg.From = baseMessage.From
-	msg.ToList = baseMessage.ToList
-	msg.Action = baseMessage.Action
-	msg.Type = baseMessage.MsgType
-	msg.originData = buf
-	return msg, err
+	return parseChatMessage(s.GetContentFromSlice(msgSlice))
 }
 
 // GetMediaData 拉取媒体消息函数
@@ -256,6 +260,11 @@ func (s *Client) GetMediaData(indexBuf string, sdkFileID string, proxy string, p
 	}, nil
 }
 
+// DownloadMedia 逐个分片拉取媒体文件并直接写入w，opts.Md5Sum不为空时校验文件的md5，临时错误时从上次成功的分片位置继续拉取
+func (s *Client) DownloadMedia(ctx stdcontext.Context, sdkFileID string, w io.Writer, opts *MediaDownloadOptions) error {
+	return DownloadMediaFrom(ctx, s, sdkFileID, w, opts)
+}
+
 // GetContentFromSlice 从切片内获取内容
 func (s *Client) GetContentFromSlice(slice *C.struct_Slice_t) []byte {
 	return C.GoBytes(unsafe.Pointer(C.GetContentFromSlice(slice)), C.GetSliceLen(slice))
@@ -14,7 +14,7 @@ import (
 type Client struct {
 }
 
-// NewClient new
+// NewClient new，当前平台不支持拉取会话存档，解密已拉取的消息请使用 NewDecryptor
 func NewClient(cfg *config.Config) (*Client, error) {
 	return nil, fmt.Errorf("会话存档功能目前只支持Linux平台运行，并且打开设置CGO_ENABLED=1")
 }
@@ -0,0 +1,204 @@
+package msgaudit
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// Message 解码后的会话存档消息，所有具体消息类型均实现了该接口
+type Message interface {
+	GetMsgID() string    // 消息id
+	GetAction() string   // 消息动作，send/recall/switch
+	GetFrom() string     // 消息发送方id
+	GetToList() []string // 消息接收方列表
+	GetRoomID() string   // 群聊消息的群id，单聊为空
+	GetMsgTime() int64   // 消息发送时间戳，ms单位
+	GetMsgType() string  // 消息类型
+}
+
+// GetMsgID 消息id
+func (b BaseMessage) GetMsgID() string { return b.MsgID }
+
+// GetAction 消息动作
+func (b BaseMessage) GetAction() string { return b.Action }
+
+// GetFrom 消息发送方id
+func (b BaseMessage) GetFrom() string { return b.From }
+
+// GetToList 消息接收方列表
+func (b BaseMessage) GetToList() []string { return b.ToList }
+
+// GetRoomID 群聊消息的群id
+func (b BaseMessage) GetRoomID() string { return b.RoomID }
+
+// GetMsgTime 消息发送时间戳
+func (b BaseMessage) GetMsgTime() int64 { return b.MsgTime }
+
+// GetMsgType 消息类型
+func (b BaseMessage) GetMsgType() string { return b.MsgType }
+
+// setBase 设置基础消息字段，用于解码会话记录与混合消息中的子消息
+func (b *BaseMessage) setBase(base BaseMessage) { *b = base }
+
+// GetMsgID 消息id
+func (s SwitchMessage) GetMsgID() string { return s.MsgID }
+
+// GetAction 消息动作，固定为switch
+func (s SwitchMessage) GetAction() string { return s.Action }
+
+// GetFrom 切换企业的成员的userid
+func (s SwitchMessage) GetFrom() string { return s.User }
+
+// GetToList 切换企业日志没有接收方
+func (s SwitchMessage) GetToList() []string { return nil }
+
+// GetRoomID 切换企业日志没有群id
+func (s SwitchMessage) GetRoomID() string { return "" }
+
+// GetMsgTime 切换企业的时间戳
+func (s SwitchMessage) GetMsgTime() int64 { return s.Time }
+
+// GetMsgType 切换企业日志没有消息类型，固定返回switch
+func (s SwitchMessage) GetMsgType() string { return "switch" }
+
+// UnknownMessage 未识别类型的消息，保留原始数据
+type UnknownMessage struct {
+	BaseMessage
+	Raw json.RawMessage // 原始消息内容
+}
+
+// messageKind 消息类型对应的解码方式
+type messageKind struct {
+	key string         // 消息内容所在的字段，为空时内容位于消息顶层
+	new func() Message // 创建对应类型的消息
+}
+
+// messageKinds 消息类型与解码方式的对应关系
+var messageKinds = map[string]messageKind{
+	"text":                 {"text", func() Message { return &TextMessage{} }},
+	"image":                {"image", func() Message { return &ImageMessage{} }},
+	"revoke":               {"revoke", func() Message { return &RevokeMessage{} }},
+	"agree":                {"agree", func() Message { return &AgreeMessage{} }},
+	"disagree":             {"disagree", func() Message { return &DisagreeMessage{} }},
+	"voice":                {"voice", func() Message { return &VoiceMessage{} }},
+	"video":                {"video", func() Message { return &VideoMessage{} }},
+	"card":                 {"card", func() Message { return &CardMessage{} }},
+	"location":             {"location", func() Message { return &LocationMessage{} }},
+	"emotion":              {"emotion", func() Message { return &EmotionMessage{} }},
+	"file":                 {"file", func() Message { return &FileMessage{} }},
+	"link":                 {"link", func() Message { return &LinkMessage{} }},
+	"weapp":                {"weapp", func() Message { return &WeappMessage{} }},
+	"chatrecord":           {"chatrecord", func() Message { return &ChatRecordMessage{} }},
+	"todo":                 {"todo", func() Message { return &TodoMessage{} }},
+	"vote":                 {"", func() Message { return &VoteMessage{} }},
+	"collect":              {"collect", func() Message { return &CollectMessage{} }},
+	"redpacket":            {"redpacket", func() Message { return &RedpacketMessage{} }},
+	"meeting":              {"meeting", func() Message { return &MeetingMessage{} }},
+	"meeting_notification": {"info", func() Message { return &MeetingNotificationMessage{} }},
+	"docmsg":               {"doc", func() Message { return &DocMessage{} }},
+	"markdown":             {"info", func() Message { return &MarkdownMessage{} }},
+	"news":                 {"info", func() Message { return &NewsMessage{} }},
+	"calendar":             {"calendar", func() Message { return &CalendarMessage{} }},
+	"mixed":                {"mixed", func() Message { return &MixedMessage{} }},
+	"meeting_voice_call":   {"meeting_voice_call", func() Message { return &MeetingVoiceCallMessage{} }},
+	"voip_doc_share":       {"voip_doc_share", func() Message { return &VoipDocShareMessage{} }},
+	"external_redpacket":   {"redpacket", func() Message { return &ExternalRedPacketMessage{} }},
+	"sphfeed":              {"sphfeed", func() Message { return &SphFeedMessage{} }},
+	"voiptext":             {"voiptext", func() Message { return &VoipTextMessage{} }},
+	"qydiskfile":           {"info", func() Message { return &QyDiskFileMessage{} }},
+}
+
+// Decode 按消息类型解码为具体的消息，如 *TextMessage、*ChatRecordMessage；切换企业日志解码为 *SwitchMessage，
+// 未识别的类型解码为 *UnknownMessage
+func (c ChatMessage) Decode() (Message, error) {
+	if c.Action == "switch" {
+		msg := &SwitchMessage{}
+		err := json.Unmarshal(c.originData, msg)
+		return msg, err
+	}
+	kind, ok := messageKinds[c.Type]
+	if !ok {
+		msg := &UnknownMessage{Raw: append(json.RawMessage(nil), c.originData...)}
+		err := json.Unmarshal(c.originData, &msg.BaseMessage)
+		return msg, err
+	}
+	msg := kind.new()
+	err := json.Unmarshal(c.originData, msg)
+	return msg, err
+}
+
+// Decode 解码会话记录中的一条消息，子消息只包含消息类型与时间
+func (r ChatRecord) Decode() (Message, error) {
+	return decodeContent(chatRecordType(r.Type), r.MsgTime, r.Content)
+}
+
+// DecodeItems 解码会话记录中的全部消息
+func (m ChatRecordMessage) DecodeItems() ([]Message, error) {
+	items := make([]Message, 0, len(m.ChatRecord.Item))
+	for _, record := range m.ChatRecord.Item {
+		item, err := record.Decode()
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// Decode 解码混合消息中的一部分，msgTime为所在混合消息的发送时间
+func (m MixedMsg) Decode(msgTime int64) (Message, error) {
+	return decodeContent(m.Type, msgTime, m.Content)
+}
+
+// DecodeItems 解码混合消息中的全部内容
+func (m MixedMessage) DecodeItems() ([]Message, error) {
+	items := make([]Message, 0, len(m.Mixed.Item))
+	for _, part := range m.Mixed.Item {
+		item, err := part.Decode(m.MsgTime)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// chatRecordType 将会话记录中的消息类型（如ChatRecordText）转换为对应的消息类型（如text）
+func chatRecordType(recordType string) string {
+	if !strings.HasPrefix(recordType, "ChatRecord") {
+		return recordType
+	}
+	msgType := strings.ToLower(strings.TrimPrefix(recordType, "ChatRecord"))
+	switch msgType {
+	case "":
+		return "chatrecord"
+	case "doc":
+		return "docmsg"
+	}
+	return msgType
+}
+
+// decodeContent 解码会话记录与混合消息中的子消息，content为对应类型消息内容字段的JSON
+func decodeContent(msgType string, msgTime int64, content string) (Message, error) {
+	base := BaseMessage{MsgType: msgType, MsgTime: msgTime}
+	if content == "" {
+		content = "{}"
+	}
+	kind, ok := messageKinds[msgType]
+	if !ok {
+		return &UnknownMessage{BaseMessage: base, Raw: json.RawMessage(content)}, nil
+	}
+	data := []byte(content)
+	if kind.key != "" {
+		var err error
+		if data, err = json.Marshal(map[string]json.RawMessage{kind.key: json.RawMessage(content)}); err != nil {
+			return nil, err
+		}
+	}
+	msg := kind.new()
+	if err := json.Unmarshal(data, msg); err != nil {
+		return nil, err
+	}
+	msg.(interface{ setBase(BaseMessage) }).setBase(base)
+	return msg, nil
+}
@@ -0,0 +1,54 @@
+package msgaudit
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChatMessageDecode(t *testing.T) {
+	chatRecord := `{"msgid":"1","action":"send","from":"zhangsan","tolist":["lisi"],"roomid":"wr1","msgtime":1603875610,"msgtype":"chatrecord","chatrecord":{"title":"群聊","item":[{"type":"ChatRecordText","msgtime":1603875611,"content":"{\"content\":\"你好\"}","from_chatroom":true},{"type":"ChatRecordMixed","msgtime":1603875612,"content":"{\"item\":[{\"type\":\"text\",\"content\":\"{\\\"content\\\":\\\"图文\\\"}\"},{\"type\":\"image\",\"content\":\"{\\\"md5sum\\\":\\\"abc\\\",\\\"sdkfileid\\\":\\\"f1\\\"}\"}]}"},{"type":"ChatRecordUnknown","msgtime":1603875613,"content":"{}"}]}}`
+	msg, err := parseChatMessage([]byte(chatRecord))
+	assert.Nil(t, err)
+	decoded, err := msg.Decode()
+	assert.Nil(t, err)
+	record, ok := decoded.(*ChatRecordMessage)
+	assert.True(t, ok)
+	assert.Equal(t, "zhangsan", record.GetFrom())
+	assert.Equal(t, []string{"lisi"}, record.GetToList())
+	assert.Equal(t, "wr1", record.GetRoomID())
+
+	items, err := record.DecodeItems()
+	assert.Nil(t, err)
+	assert.Len(t, items, 3)
+	assert.Equal(t, "你好", items[0].(*TextMessage).Text.Content)
+	assert.Equal(t, int64(1603875611), items[0].GetMsgTime())
+	mixed := items[1].(*MixedMessage)
+	parts, err := mixed.DecodeItems()
+	assert.Nil(t, err)
+	assert.Equal(t, "图文", parts[0].(*TextMessage).Text.Content)
+	assert.Equal(t, "f1", parts[1].(*ImageMessage).Image.SdkFileID)
+	assert.Equal(t, int64(1603875612), parts[1].GetMsgTime())
+	assert.Equal(t, "unknown", items[2].(*UnknownMessage).GetMsgType())
+
+	msg, err = parseChatMessage([]byte(`{"msgid":"2","action":"send","from":"zhangsan","msgtype":"sphfeed","sphfeed":{"feed_type":4,"sph_name":"视频号","feed_desc":"描述"}}`))
+	assert.Nil(t, err)
+	decoded, err = msg.Decode()
+	assert.Nil(t, err)
+	assert.Equal(t, "视频号", decoded.(*SphFeedMessage).SphFeed.SphName)
+
+	msg, err = parseChatMessage([]byte(`{"msgid":"3","action":"switch","time":1603875614,"user":"wangwu"}`))
+	assert.Nil(t, err)
+	decoded, err = msg.Decode()
+	assert.Nil(t, err)
+	assert.Equal(t, "wangwu", decoded.GetFrom())
+	assert.Equal(t, int64(1603875614), decoded.GetMsgTime())
+
+	msg, err = parseChatMessage([]byte(`{"msgid":"4","action":"send","from":"zhangsan","msgtype":"newtype","newtype":{"a":1}}`))
+	assert.Nil(t, err)
+	decoded, err = msg.Decode()
+	assert.Nil(t, err)
+	unknown := decoded.(*UnknownMessage)
+	assert.Equal(t, "newtype", unknown.GetMsgType())
+	assert.Contains(t, string(unknown.Raw), `"newtype":{"a":1}`)
+}
@@ -0,0 +1,89 @@
+package msgaudit
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/base64"
+	"fmt"
+)
+
+// messagePaddingBlockSize 消息明文使用PKCS#7填充，填充的块大小为32字节
+const messagePaddingBlockSize = 32
+
+// Decryptor 纯Go实现的会话存档消息解密器，不依赖C版SDK，可用于解密其他服务拉取的ChatData
+type Decryptor struct {
+	keyRing *KeyRing
+}
+
+// NewDecryptor 创建解密器，keyRing中需要包含消息加密公钥对应版本的私钥
+func NewDecryptor(keyRing *KeyRing) *Decryptor {
+	return &Decryptor{keyRing: keyRing}
+}
+
+// NewDecryptorWithKey 使用单个私钥创建解密器，私钥作为私钥环的默认私钥
+func NewDecryptorWithKey(privateKey string) (*Decryptor, error) {
+	keyRing := NewKeyRing()
+	if err := keyRing.SetDefault(privateKey); err != nil {
+		return nil, err
+	}
+	return NewDecryptor(keyRing), nil
+}
+
+// KeyRing 返回解密器使用的私钥环
+func (d *Decryptor) KeyRing() *KeyRing {
+	return d.keyRing
+}
+
+// DecryptChatData 使用 ChatData.PublickeyVer 对应版本的私钥解密消息
+func (d *Decryptor) DecryptChatData(data ChatData) (ChatMessage, error) {
+	encryptKey, err := d.keyRing.DecryptRandomKey(data.PublickeyVer, data.EncryptRandomKey)
+	if err != nil {
+		return ChatMessage{}, err
+	}
+	return decryptChatMessage(encryptKey, data.EncryptChatMsg)
+}
+
+// DecryptData 未指定公钥版本时解密消息，依次尝试默认私钥与私钥环中的各版本私钥
+func (d *Decryptor) DecryptData(encryptRandomKey string, encryptMsg string) (ChatMessage, error) {
+	encryptKey, err := d.keyRing.TryDecryptRandomKey(encryptRandomKey)
+	if err != nil {
+		return ChatMessage{}, err
+	}
+	return decryptChatMessage(encryptKey, encryptMsg)
+}
+
+// DecryptMessage 使用RSA解密后的encrypt_random_key解密encrypt_chat_msg，返回消息明文
+// 消息使用AES-256-CBC加密，密钥为encrypt_random_key解密后的内容，IV为密钥的前16字节
+func DecryptMessage(encryptKey []byte, encryptMsg string) ([]byte, error) {
+	if len(encryptKey) != 32 {
+		return nil, fmt.Errorf("invalid encrypt key length %d, want 32", len(encryptKey))
+	}
+	ciphertext, err := base64.StdEncoding.DecodeString(encryptMsg)
+	if err != nil {
+		return nil, err
+	}
+	if len(ciphertext) == 0 || len(ciphertext)%aes.BlockSize != 0 {
+		return nil, fmt.Errorf("ciphertext is not a multiple of the block size")
+	}
+	block, err := aes.NewCipher(encryptKey)
+	if err != nil {
+		return nil, err
+	}
+	plaintext := make([]byte, len(ciphertext))
+	cipher.NewCBCDecrypter(block, encryptKey[:aes.BlockSize]).CryptBlocks(plaintext, ciphertext)
+
+	pad := int(plaintext[len(plaintext)-1])
+	if pad < 1 || pad > messagePaddingBlockSize || pad > len(plaintext) {
+		return nil, NewSDKErr(10006)
+	}
+	return plaintext[:len(plaintext)-pad], nil
+}
+
+// decryptChatMessage 解密并解析消息
+func decryptChatMessage(encryptKey []byte, encryptMsg string) (ChatMessage, error) {
+	plaintext, err := DecryptMessage(encryptKey, encryptMsg)
+	if err != nil {
+		return ChatMessage{}, err
+	}
+	return parseChatMessage(plaintext)
+}
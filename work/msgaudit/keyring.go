@@ -0,0 +1,136 @@
+package msgaudit
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrUnknownKeyVersion 找不到消息加密版本对应的私钥
+var ErrUnknownKeyVersion = errors.New("unknown publickey_ver")
+
+// KeyRing 按公钥版本号管理的消息加密私钥，企业轮换公钥后历史消息仍需使用旧版本私钥解密
+type KeyRing struct {
+	mu         sync.RWMutex
+	keys       map[uint32]*rsa.PrivateKey
+	defaultKey *rsa.PrivateKey
+}
+
+// NewKeyRing 创建私钥环
+func NewKeyRing() *KeyRing {
+	return &KeyRing{keys: make(map[uint32]*rsa.PrivateKey)}
+}
+
+// Add 添加指定公钥版本对应的PEM格式私钥，支持PKCS#1与PKCS#8
+func (k *KeyRing) Add(version uint32, privateKey string) error {
+	key, err := ParsePrivateKey(privateKey)
+	if err != nil {
+		return fmt.Errorf("publickey_ver %d: %w", version, err)
+	}
+	k.mu.Lock()
+	k.keys[version] = key
+	k.mu.Unlock()
+	return nil
+}
+
+// SetDefault 设置默认私钥，找不到版本对应的私钥时使用，用于兼容只配置了单个私钥的场景
+func (k *KeyRing) SetDefault(privateKey string) error {
+	key, err := ParsePrivateKey(privateKey)
+	if err != nil {
+		return err
+	}
+	k.mu.Lock()
+	k.defaultKey = key
+	k.mu.Unlock()
+	return nil
+}
+
+// Versions 返回已添加私钥的公钥版本号
+func (k *KeyRing) Versions() []uint32 {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	versions := make([]uint32, 0, len(k.keys))
+	for version := range k.keys {
+		versions = append(versions, version)
+	}
+	return versions
+}
+
+// DecryptRandomKey 使用version对应的私钥解密base64编码的encrypt_random_key
+func (k *KeyRing) DecryptRandomKey(version uint32, encryptRandomKey string) ([]byte, error) {
+	k.mu.RLock()
+	key, ok := k.keys[version]
+	if !ok {
+		key = k.defaultKey
+	}
+	k.mu.RUnlock()
+	if key == nil {
+		return nil, fmt.Errorf("%w: no private key for version %d", ErrUnknownKeyVersion, version)
+	}
+
+	ciphertext, err := base64.StdEncoding.DecodeString(encryptRandomKey)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := rsa.DecryptPKCS1v15(rand.Reader, key, ciphertext)
+	if err != nil {
+		if !ok {
+			return nil, fmt.Errorf("%w: no private key for version %d and the default key failed: %v", ErrUnknownKeyVersion, version, err)
+		}
+		return nil, fmt.Errorf("decrypt encrypt_random_key with publickey_ver %d: %w", version, err)
+	}
+	return plaintext, nil
+}
+
+// TryDecryptRandomKey 未知公钥版本时，依次尝试默认私钥与各版本私钥解密encrypt_random_key
+func (k *KeyRing) TryDecryptRandomKey(encryptRandomKey string) ([]byte, error) {
+	ciphertext, err := base64.StdEncoding.DecodeString(encryptRandomKey)
+	if err != nil {
+		return nil, err
+	}
+	k.mu.RLock()
+	candidates := make([]*rsa.PrivateKey, 0, len(k.keys)+1)
+	if k.defaultKey != nil {
+		candidates = append(candidates, k.defaultKey)
+	}
+	for _, key := range k.keys {
+		candidates = append(candidates, key)
+	}
+	k.mu.RUnlock()
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("%w: key ring is empty", ErrUnknownKeyVersion)
+	}
+
+	for _, key := range candidates {
+		var plaintext []byte
+		if plaintext, err = rsa.DecryptPKCS1v15(rand.Reader, key, ciphertext); err == nil {
+			return plaintext, nil
+		}
+	}
+	return nil, fmt.Errorf("decrypt encrypt_random_key with %d private keys: %w", len(candidates), err)
+}
+
+// ParsePrivateKey 解析PEM格式的RSA私钥，支持PKCS#1与PKCS#8
+func ParsePrivateKey(privateKey string) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode([]byte(privateKey))
+	if block == nil {
+		return nil, errors.New("PrivateKey format error")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("parse private key: %w", err)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("not supported privatekey format, should be *rsa.PrivateKey, got %T", parsed)
+	}
+	return key, nil
+}
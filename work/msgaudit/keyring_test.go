@@ -0,0 +1,57 @@
+package msgaudit
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func generateKeyForTest(t *testing.T, pkcs8 bool) (*rsa.PrivateKey, string) {
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	assert.Nil(t, err)
+	block := &pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}
+	if pkcs8 {
+		der, err := x509.MarshalPKCS8PrivateKey(key)
+		assert.Nil(t, err)
+		block = &pem.Block{Type: "PRIVATE KEY", Bytes: der}
+	}
+	return key, string(pem.EncodeToMemory(block))
+}
+
+func encryptRandomKeyForTest(t *testing.T, key *rsa.PrivateKey, randomKey string) string {
+	ciphertext, err := rsa.EncryptPKCS1v15(rand.Reader, &key.PublicKey, []byte(randomKey))
+	assert.Nil(t, err)
+	return base64.StdEncoding.EncodeToString(ciphertext)
+}
+
+func TestKeyRing(t *testing.T) {
+	oldKey, oldPEM := generateKeyForTest(t, false)
+	newKey, newPEM := generateKeyForTest(t, true)
+
+	ring := NewKeyRing()
+	assert.Nil(t, ring.Add(1, oldPEM))
+	assert.Nil(t, ring.Add(2, newPEM))
+	assert.NotNil(t, ring.Add(3, "invalid"))
+	assert.ElementsMatch(t, []uint32{1, 2}, ring.Versions())
+
+	plaintext, err := ring.DecryptRandomKey(1, encryptRandomKeyForTest(t, oldKey, "old-random-key"))
+	assert.Nil(t, err)
+	assert.Equal(t, "old-random-key", string(plaintext))
+
+	plaintext, err = ring.DecryptRandomKey(2, encryptRandomKeyForTest(t, newKey, "new-random-key"))
+	assert.Nil(t, err)
+	assert.Equal(t, "new-random-key", string(plaintext))
+
+	_, err = ring.DecryptRandomKey(3, encryptRandomKeyForTest(t, newKey, "new-random-key"))
+	assert.True(t, errors.Is(err, ErrUnknownKeyVersion))
+
+	plaintext, err = ring.TryDecryptRandomKey(encryptRandomKeyForTest(t, oldKey, "old-random-key"))
+	assert.Nil(t, err)
+	assert.Equal(t, "old-random-key", string(plaintext))
+}
@@ -1,8 +1,111 @@
 package msgaudit
 
+import (
+	stdcontext "context"
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+const (
+	// defaultMediaMaxRetries 默认的媒体分片拉取失败重试次数
+	defaultMediaMaxRetries = 3
+)
+
 // MediaData 媒体文件数据
 type MediaData struct {
 	OutIndexBuf string `json:"outindexbuf,omitempty"`
 	IsFinish    bool   `json:"is_finish,omitempty"`
 	Data        []byte `json:"data,omitempty"`
 }
+
+// MediaSource 媒体数据源，*Client 实现了该接口
+type MediaSource interface {
+	GetMediaData(indexBuf string, sdkFileID string, proxy string, passwd string, timeout int) (*MediaData, error)
+}
+
+// MediaDownloadOptions 媒体文件下载配置
+type MediaDownloadOptions struct {
+	Md5Sum        string        // 消息中的md5sum，不为空时下载完成后进行校验
+	Proxy         string        // 代理地址，如：socks5://10.0.0.1:8081
+	Passwd        string        // 代理账号密码，如：user_name:passwd_123
+	Timeout       int           // 每个分片的拉取超时时间，单位秒，默认5秒
+	MaxRetries    int           // 每个分片因网络等临时错误失败时的最大重试次数，默认3次
+	RetryInterval time.Duration // 首次重试间隔，默认1秒，之后每次翻倍
+}
+
+// DownloadMediaFrom 从source逐个分片拉取媒体文件并写入w，临时错误时从上次成功的分片位置继续拉取
+// 出错时w中可能已写入部分数据
+func DownloadMediaFrom(ctx stdcontext.Context, source MediaSource, sdkFileID string, w io.Writer, opts *MediaDownloadOptions) error {
+	var options MediaDownloadOptions
+	if opts != nil {
+		options = *opts
+	}
+	if options.Timeout <= 0 {
+		options.Timeout = defaultPullTimeout
+	}
+	if options.MaxRetries <= 0 {
+		options.MaxRetries = defaultMediaMaxRetries
+	}
+	if options.RetryInterval <= 0 {
+		options.RetryInterval = defaultRetryInterval
+	}
+
+	hash := md5.New()
+	writer := io.MultiWriter(w, hash)
+	indexBuf := ""
+	for {
+		mediaData, err := getMediaChunk(ctx, source, indexBuf, sdkFileID, &options)
+		if err != nil {
+			return err
+		}
+		if _, err = writer.Write(mediaData.Data); err != nil {
+			return err
+		}
+		if mediaData.IsFinish {
+			break
+		}
+		indexBuf = mediaData.OutIndexBuf
+	}
+
+	if options.Md5Sum != "" {
+		if sum := hex.EncodeToString(hash.Sum(nil)); !strings.EqualFold(sum, options.Md5Sum) {
+			return fmt.Errorf("md5 mismatch for media %s: got %s, want %s", sdkFileID, sum, options.Md5Sum)
+		}
+	}
+	return nil
+}
+
+// getMediaChunk 拉取indexBuf位置的分片，临时错误时按指数退避重试
+func getMediaChunk(ctx stdcontext.Context, source MediaSource, indexBuf, sdkFileID string, opts *MediaDownloadOptions) (*MediaData, error) {
+	interval := opts.RetryInterval
+	for attempt := 0; ; attempt++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		mediaData, err := source.GetMediaData(indexBuf, sdkFileID, opts.Proxy, opts.Passwd, opts.Timeout)
+		if err == nil {
+			return mediaData, nil
+		}
+		if attempt >= opts.MaxRetries || !isTemporaryErr(err) {
+			return nil, fmt.Errorf("get media data %s: %w", sdkFileID, err)
+		}
+		if err = sleepContext(ctx, interval); err != nil {
+			return nil, err
+		}
+		interval *= 2
+	}
+}
+
+// isTemporaryErr 判断SDK错误是否可以重试，网络错误与系统失败可以重试，非SDK错误也视为可以重试
+func isTemporaryErr(err error) bool {
+	var sdkErr Error
+	if !errors.As(err, &sdkErr) {
+		return true
+	}
+	return sdkErr.ErrCode == 10001 || sdkErr.ErrCode == 10003
+}
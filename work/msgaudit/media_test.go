@@ -0,0 +1,55 @@
+package msgaudit
+
+import (
+	"bytes"
+	stdcontext "context"
+	"crypto/md5"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeMediaSource struct {
+	chunks [][]byte
+	fails  map[string]int
+}
+
+func (s *fakeMediaSource) GetMediaData(indexBuf string, sdkFileID string, proxy string, passwd string, timeout int) (*MediaData, error) {
+	if s.fails[indexBuf] > 0 {
+		s.fails[indexBuf]--
+		return nil, NewSDKErr(10001)
+	}
+	index := 0
+	if indexBuf != "" {
+		index = int(indexBuf[0] - '0')
+	}
+	return &MediaData{
+		Data:        s.chunks[index],
+		OutIndexBuf: string(rune('0' + index + 1)),
+		IsFinish:    index == len(s.chunks)-1,
+	}, nil
+}
+
+func TestDownloadMediaFrom(t *testing.T) {
+	source := &fakeMediaSource{
+		chunks: [][]byte{[]byte("hello "), []byte("media "), []byte("data")},
+		fails:  map[string]int{"1": 2},
+	}
+	sum := md5.Sum([]byte("hello media data"))
+	opts := &MediaDownloadOptions{Md5Sum: hex.EncodeToString(sum[:]), RetryInterval: time.Millisecond}
+
+	buffer := &bytes.Buffer{}
+	err := DownloadMediaFrom(stdcontext.Background(), source, "file", buffer, opts)
+	assert.Nil(t, err)
+	assert.Equal(t, "hello media data", buffer.String())
+
+	opts.Md5Sum = "0123456789abcdef0123456789abcdef"
+	err = DownloadMediaFrom(stdcontext.Background(), source, "file", &bytes.Buffer{}, opts)
+	assert.NotNil(t, err)
+
+	source.fails["2"] = 10
+	err = DownloadMediaFrom(stdcontext.Background(), source, "file", &bytes.Buffer{}, &MediaDownloadOptions{RetryInterval: time.Millisecond})
+	assert.NotNil(t, err)
+}
@@ -280,7 +280,44 @@ type SphFeedMessage struct {
 		FeedType uint32 `json:"feed_type,omitempty"` // 视频号消息类型。2 图片、4 视频、9 直播。Uint32类型
 		SphName  string `json:"sph_name,omitempty"`  // 视频号账号名称。String类型
 		FeedDesc string `json:"feed_desc,omitempty"` // 视频号消息描述。String类型
-	}
+	} `json:"sphfeed,omitempty"`
+}
+
+// DisagreeMessage 不同意会话聊天内容
+type DisagreeMessage struct {
+	BaseMessage
+	Disagree struct {
+		UserID       string `json:"userid,omitempty"`        // 不同意协议者的userid，外部企业默认为external_userid。
+		DisagreeTime int64  `json:"disagree_time,omitempty"` // 不同意协议的时间，utc时间，ms单位。
+	} `json:"disagree,omitempty"`
+}
+
+// MeetingNotificationMessage 会议控制消息
+type MeetingNotificationMessage struct {
+	BaseMessage
+	Info struct {
+		MeetingID        uint64 `json:"meeting_id,omitempty"`        // 会议id
+		NotificationType uint32 `json:"notification_type,omitempty"` // 会议控制消息类型
+		Content          string `json:"content,omitempty"`           // 会议控制消息内容
+	} `json:"info,omitempty"`
+}
+
+// VoipTextMessage 音视频通话消息
+type VoipTextMessage struct {
+	BaseMessage
+	VoipID   string `json:"voipid,omitempty"` // 音视频通话id
+	VoipText struct {
+		CallDuration uint32 `json:"callduration,omitempty"` // 通话时长，单位秒
+		InviteType   uint32 `json:"invitetype,omitempty"`   // 通话类型。1 单人视频通话、2 单人语音通话、3 多人视频通话、4 多人语音通话
+	} `json:"voiptext,omitempty"`
+}
+
+// QyDiskFileMessage 微盘文件消息
+type QyDiskFileMessage struct {
+	BaseMessage
+	Info struct {
+		FileName string `json:"filename,omitempty"` // 微盘文件名称
+	} `json:"info,omitempty"`
 }
 
 // SwitchMessage 企业切换日志
@@ -0,0 +1,308 @@
+package msgaudit
+
+import (
+	stdcontext "context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/silenceper/wechat/v2/cache"
+)
+
+const (
+	// defaultPullLimit 默认每次拉取的消息条数
+	defaultPullLimit = 100
+	// maxPullLimit 每次拉取的最大消息条数
+	maxPullLimit = 1000
+	// defaultPullTimeout 默认的拉取超时时间，单位秒
+	defaultPullTimeout = 5
+	// defaultPollInterval 没有新消息时默认的轮询间隔
+	defaultPollInterval = 3 * time.Second
+	// defaultRetryInterval 出错后默认的首次重试间隔，之后每次翻倍
+	defaultRetryInterval = time.Second
+	// defaultMaxRetryInterval 默认的最大重试间隔
+	defaultMaxRetryInterval = time.Minute
+	// defaultCheckpointTTL 默认的检查点缓存有效期，每次拉取成功后都会刷新
+	defaultCheckpointTTL = 30 * 24 * time.Hour
+)
+
+// ChatSource 会话存档数据源，*Client 实现了该接口
+type ChatSource interface {
+	GetChatData(seq uint64, limit uint64, proxy string, passwd string, timeout int) ([]ChatData, error)
+	DecryptChatData(data ChatData) (ChatMessage, error)
+}
+
+// CheckpointStore 拉取进度的持久化存储，保存已处理的最大seq
+type CheckpointStore interface {
+	Load(ctx stdcontext.Context) (uint64, error)
+	Save(ctx stdcontext.Context, seq uint64) error
+}
+
+// CacheCheckpointStore 基于cache.Cache的检查点存储
+type CacheCheckpointStore struct {
+	cache cache.Cache
+	key   string
+	ttl   time.Duration
+}
+
+// NewCacheCheckpointStore 创建基于cache.Cache的检查点存储，ttl<=0时使用默认的30天
+func NewCacheCheckpointStore(c cache.Cache, key string, ttl time.Duration) *CacheCheckpointStore {
+	if ttl <= 0 {
+		ttl = defaultCheckpointTTL
+	}
+	return &CacheCheckpointStore{cache: c, key: key, ttl: ttl}
+}
+
+// Load 读取检查点，不存在时返回0
+func (s *CacheCheckpointStore) Load(ctx stdcontext.Context) (uint64, error) {
+	switch val := s.cache.Get(s.key).(type) {
+	case nil:
+		return 0, nil
+	case uint64:
+		return val, nil
+	case string:
+		return strconv.ParseUint(val, 10, 64)
+	case []byte:
+		return strconv.ParseUint(string(val), 10, 64)
+	default:
+		return 0, fmt.Errorf("unexpected checkpoint value %v of type %T", val, val)
+	}
+}
+
+// Save 保存检查点，以字符串形式存储以兼容各类缓存
+func (s *CacheCheckpointStore) Save(ctx stdcontext.Context, seq uint64) error {
+	return s.cache.Set(s.key, strconv.FormatUint(seq, 10), s.ttl)
+}
+
+// PulledMessage 拉取并解密后的一条消息
+type PulledMessage struct {
+	ChatData
+	Message ChatMessage
+}
+
+// PullerOptions 持续拉取的配置
+type PullerOptions struct {
+	Limit            uint64        // 每次拉取的消息条数，默认100，最大1000
+	Proxy            string        // 代理地址，如：socks5://10.0.0.1:8081
+	Passwd           string        // 代理账号密码，如：user_name:passwd_123
+	Timeout          int           // 拉取超时时间，单位秒，默认5秒
+	PollInterval     time.Duration // 没有新消息时的轮询间隔，默认3秒
+	RetryInterval    time.Duration // 出错后的首次重试间隔，默认1秒，之后每次翻倍
+	MaxRetryInterval time.Duration // 最大重试间隔，默认1分钟
+	// OnError 拉取或处理出错时回调，出错的操作会在退避后重试
+	OnError func(err error)
+	// OnDecryptError 消息解密失败时回调，返回nil时跳过该消息，返回错误时停止拉取；为空时停止拉取
+	OnDecryptError func(data ChatData, err error) error
+}
+
+// PullerStats 拉取状态统计
+type PullerStats struct {
+	Checkpoint      uint64        // 已处理的最大seq
+	Pulled          uint64        // 已拉取的消息数
+	Handled         uint64        // 已成功处理的消息数
+	Skipped         uint64        // 因解密失败被跳过的消息数
+	Errors          uint64        // 出错次数
+	LastPullTime    time.Time     // 最近一次成功拉取的时间
+	LastMessageTime time.Time     // 最近一条已处理消息的发送时间
+	Lag             time.Duration // 最近一条已处理消息距当前的延迟，已追上最新消息时为0
+	CaughtUp        bool          // 最近一次拉取是否已没有更多消息
+}
+
+// Puller 会话存档持续拉取器，按seq顺序解密并投递消息，处理成功后推进检查点，保证至少一次投递
+type Puller struct {
+	source ChatSource
+	store  CheckpointStore
+	opts   PullerOptions
+
+	mu    sync.Mutex
+	stats PullerStats
+}
+
+// NewPuller 创建持续拉取器
+func NewPuller(source ChatSource, store CheckpointStore, opts *PullerOptions) *Puller {
+	p := &Puller{source: source, store: store}
+	if opts != nil {
+		p.opts = *opts
+	}
+	if p.opts.Limit == 0 {
+		p.opts.Limit = defaultPullLimit
+	}
+	if p.opts.Limit > maxPullLimit {
+		p.opts.Limit = maxPullLimit
+	}
+	if p.opts.Timeout <= 0 {
+		p.opts.Timeout = defaultPullTimeout
+	}
+	if p.opts.PollInterval <= 0 {
+		p.opts.PollInterval = defaultPollInterval
+	}
+	if p.opts.RetryInterval <= 0 {
+		p.opts.RetryInterval = defaultRetryInterval
+	}
+	if p.opts.MaxRetryInterval <= 0 {
+		p.opts.MaxRetryInterval = defaultMaxRetryInterval
+	}
+	return p
+}
+
+// Stats 返回当前的拉取状态统计
+func (p *Puller) Stats() PullerStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	stats := p.stats
+	if !stats.CaughtUp && !stats.LastMessageTime.IsZero() {
+		stats.Lag = time.Since(stats.LastMessageTime)
+	}
+	return stats
+}
+
+// Run 持续拉取消息并依次调用handler，handler返回错误时在退避后重试同一条消息；ctx取消时返回ctx.Err()
+func (p *Puller) Run(ctx stdcontext.Context, handler func(ctx stdcontext.Context, msg *PulledMessage) error) error {
+	seq, err := p.store.Load(ctx)
+	if err != nil {
+		return fmt.Errorf("load checkpoint: %w", err)
+	}
+	p.setCheckpoint(seq)
+
+	for {
+		var dataList []ChatData
+		if err = p.retry(ctx, func() (err error) {
+			dataList, err = p.source.GetChatData(seq, p.opts.Limit, p.opts.Proxy, p.opts.Passwd, p.opts.Timeout)
+			return err
+		}); err != nil {
+			return err
+		}
+		p.mu.Lock()
+		p.stats.Pulled += uint64(len(dataList))
+		p.stats.LastPullTime = time.Now()
+		p.stats.CaughtUp = uint64(len(dataList)) < p.opts.Limit
+		p.mu.Unlock()
+
+		for _, data := range dataList {
+			if err = p.deliver(ctx, data, handler); err != nil {
+				p.saveOnExit(seq)
+				return err
+			}
+			seq = data.Seq
+		}
+		if ctx.Err() != nil {
+			p.saveOnExit(seq)
+			return ctx.Err()
+		}
+		// 每次拉取成功后都保存检查点，同时刷新缓存的有效期
+		if err = p.retry(ctx, func() error {
+			return p.store.Save(ctx, seq)
+		}); err != nil {
+			p.saveOnExit(seq)
+			return err
+		}
+		p.setCheckpoint(seq)
+
+		if uint64(len(dataList)) < p.opts.Limit {
+			if err = sleepContext(ctx, p.opts.PollInterval); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// RunChan 持续拉取消息并发送到ch，消息写入ch即视为处理成功；ctx取消时返回ctx.Err()，不会关闭ch
+func (p *Puller) RunChan(ctx stdcontext.Context, ch chan<- *PulledMessage) error {
+	return p.Run(ctx, func(ctx stdcontext.Context, msg *PulledMessage) error {
+		select {
+		case ch <- msg:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
+}
+
+// deliver 解密一条消息并调用handler直至成功
+func (p *Puller) deliver(ctx stdcontext.Context, data ChatData, handler func(ctx stdcontext.Context, msg *PulledMessage) error) error {
+	message, err := p.source.DecryptChatData(data)
+	if err != nil {
+		err = fmt.Errorf("decrypt message seq=%d msgid=%s: %w", data.Seq, data.MsgID, err)
+		if p.opts.OnDecryptError == nil {
+			return err
+		}
+		if err = p.opts.OnDecryptError(data, err); err != nil {
+			return err
+		}
+		p.mu.Lock()
+		p.stats.Skipped++
+		p.mu.Unlock()
+		return nil
+	}
+
+	msg := &PulledMessage{ChatData: data, Message: message}
+	if err = p.retry(ctx, func() error {
+		return handler(ctx, msg)
+	}); err != nil {
+		return err
+	}
+	p.mu.Lock()
+	p.stats.Handled++
+	if message.MsgTime > 0 {
+		p.stats.LastMessageTime = time.UnixMilli(message.MsgTime)
+	}
+	p.mu.Unlock()
+	return nil
+}
+
+// saveOnExit 退出前尽量保存已处理的进度，保存失败时这部分消息会在下次启动后重新投递
+func (p *Puller) saveOnExit(seq uint64) {
+	if err := p.store.Save(stdcontext.Background(), seq); err == nil {
+		p.setCheckpoint(seq)
+	}
+}
+
+// setCheckpoint 更新统计中的检查点
+func (p *Puller) setCheckpoint(seq uint64) {
+	p.mu.Lock()
+	p.stats.Checkpoint = seq
+	p.mu.Unlock()
+}
+
+// retry 执行fn直至成功，失败时按指数退避重试，ctx取消时返回ctx.Err()
+func (p *Puller) retry(ctx stdcontext.Context, fn func() error) error {
+	interval := p.opts.RetryInterval
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		err := fn()
+		if err == nil {
+			return nil
+		}
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		p.mu.Lock()
+		p.stats.Errors++
+		p.mu.Unlock()
+		if p.opts.OnError != nil {
+			p.opts.OnError(err)
+		}
+		if err = sleepContext(ctx, interval); err != nil {
+			return err
+		}
+		interval *= 2
+		if interval > p.opts.MaxRetryInterval {
+			interval = p.opts.MaxRetryInterval
+		}
+	}
+}
+
+// sleepContext 等待d或ctx取消
+func sleepContext(ctx stdcontext.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
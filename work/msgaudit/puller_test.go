@@ -0,0 +1,83 @@
+package msgaudit
+
+import (
+	stdcontext "context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/silenceper/wechat/v2/cache"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeSource struct {
+	data      []ChatData
+	failPulls int
+}
+
+func (s *fakeSource) GetChatData(seq uint64, limit uint64, proxy string, passwd string, timeout int) ([]ChatData, error) {
+	if s.failPulls > 0 {
+		s.failPulls--
+		return nil, NewSDKErr(10001)
+	}
+	var result []ChatData
+	for _, data := range s.data {
+		if data.Seq > seq && uint64(len(result)) < limit {
+			result = append(result, data)
+		}
+	}
+	return result, nil
+}
+
+func (s *fakeSource) DecryptChatData(data ChatData) (ChatMessage, error) {
+	if data.EncryptChatMsg == "bad" {
+		return ChatMessage{}, NewSDKErr(10006)
+	}
+	return ChatMessage{ID: data.EncryptChatMsg, MsgTime: time.Now().UnixMilli()}, nil
+}
+
+func TestPuller(t *testing.T) {
+	source := &fakeSource{
+		failPulls: 1,
+		data: []ChatData{
+			{Seq: 1, EncryptChatMsg: "m1"},
+			{Seq: 2, EncryptChatMsg: "bad"},
+			{Seq: 3, EncryptChatMsg: "m3"},
+			{Seq: 5, EncryptChatMsg: "m5"},
+		},
+	}
+	store := NewCacheCheckpointStore(cache.NewMemory(), "msgaudit_seq", 0)
+	puller := NewPuller(source, store, &PullerOptions{
+		Limit:          2,
+		PollInterval:   time.Millisecond,
+		RetryInterval:  time.Millisecond,
+		OnDecryptError: func(data ChatData, err error) error { return nil },
+	})
+
+	ctx, cancel := stdcontext.WithCancel(stdcontext.Background())
+	defer cancel()
+	var received []string
+	handlerFailed := false
+	err := puller.Run(ctx, func(ctx stdcontext.Context, msg *PulledMessage) error {
+		if msg.Seq == 3 && !handlerFailed {
+			handlerFailed = true
+			return errors.New("temporary failure")
+		}
+		received = append(received, msg.Message.ID)
+		if msg.Seq == 5 {
+			cancel()
+		}
+		return nil
+	})
+	assert.True(t, errors.Is(err, stdcontext.Canceled))
+	assert.Equal(t, []string{"m1", "m3", "m5"}, received)
+
+	checkpoint, err := store.Load(stdcontext.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(5), checkpoint)
+
+	stats := puller.Stats()
+	assert.Equal(t, uint64(3), stats.Handled)
+	assert.Equal(t, uint64(1), stats.Skipped)
+	assert.Equal(t, uint64(2), stats.Errors)
+}
@@ -39,15 +39,18 @@ type WebhookSendImageOption struct {
 type WebhookSendNewsOption struct {
 	MsgType string `json:"msgtype"` // 消息类型,此时固定为news
 	News    struct {
-		Articles []struct {
-			Title       string `json:"title"`       // 标题，不超过128个字节，超过会自动截断
-			Description string `json:"description"` // 描述，不超过512个字节，超过会自动截断
-			URL         string `json:"url"`         // 点击后跳转的链接
-			PicURL      string `json:"picurl"`      // 图文消息的图片链接，支持JPG、PNG格式，较好的效果为大图 1068*455，小图150*150
-		} `json:"articles"` // 图文消息列表 一个图文消息支持1到8条图文
+		Articles []WebhookNewsArticle `json:"articles"` // 图文消息列表 一个图文消息支持1到8条图文
 	} `json:"news"` // 图文消息内容
 }
 
+// WebhookNewsArticle 机器人图文消息中的一条图文
+type WebhookNewsArticle struct {
+	Title       string `json:"title"`       // 标题，不超过128个字节，超过会自动截断
+	Description string `json:"description"` // 描述，不超过512个字节，超过会自动截断
+	URL         string `json:"url"`         // 点击后跳转的链接
+	PicURL      string `json:"picurl"`      // 图文消息的图片链接，支持JPG、PNG格式，较好的效果为大图 1068*455，小图150*150
+}
+
 // WebhookSendFileOption 机器人发送文件消息请求参数
 type WebhookSendFileOption struct {
 	MsgType string `json:"msgtype"` // 消息类型，此时固定为file
@@ -56,6 +59,14 @@ type WebhookSendFileOption struct {
 	} `json:"file"` // 文件类型
 }
 
+// WebhookSendVoiceOption 机器人发送语音消息请求参数
+type WebhookSendVoiceOption struct {
+	MsgType string `json:"msgtype"` // 消息类型，此时固定为voice
+	Voice   struct {
+		MediaID string `json:"media_id"` // 语音文件id，通过文件上传接口获取
+	} `json:"voice"` // 语音类型
+}
+
 // WebHookSendTempNoticeOption 机器人发送文本通知模版消息请求参数
 type WebHookSendTempNoticeOption struct {
 	MsgType      string       `json:"msgtype"`       // 消息类型，此时的消息类型固定为template_card
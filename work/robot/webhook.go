@@ -0,0 +1,198 @@
+package robot
+
+import (
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+
+	"github.com/silenceper/wechat/v2/util"
+)
+
+const (
+	// webhookUploadMediaURL 机器人上传文件
+	webhookUploadMediaURL = "https://qyapi.weixin.qq.com/cgi-bin/webhook/upload_media?key=%s&type=%s"
+)
+
+const (
+	// MaxTextContentBytes 文本消息内容的最大字节数
+	MaxTextContentBytes = 2048
+	// MaxMarkdownContentBytes markdown消息内容的最大字节数
+	MaxMarkdownContentBytes = 4096
+	// MaxImageBytes 图片（base64编码前）的最大字节数
+	MaxImageBytes = 2 * 1024 * 1024
+
+	// MentionAll 提醒所有人
+	MentionAll = "@all"
+
+	// MediaTypeFile 普通文件，大小不超过20M
+	MediaTypeFile = "file"
+	// MediaTypeVoice 语音，大小不超过2M，播放长度不超过60s，仅支持AMR格式
+	MediaTypeVoice = "voice"
+)
+
+// Webhook 群机器人，仅依赖webhook地址中的key，不需要企业的access_token
+type Webhook struct {
+	key string
+}
+
+// NewWebhook 通过webhook地址中的key初始化群机器人
+func NewWebhook(key string) *Webhook {
+	return &Webhook{key: key}
+}
+
+// Webhook 获取webhookKey对应的群机器人
+func (r *Client) Webhook(webhookKey string) *Webhook {
+	return NewWebhook(webhookKey)
+}
+
+// Send 发送任意类型的消息，options为 send_option.go 中的消息参数
+// @see https://developer.work.weixin.qq.com/document/path/91770
+func (w *Webhook) Send(options interface{}) error {
+	response, err := util.PostJSON(fmt.Sprintf(webhookSendURL, w.key), options)
+	if err != nil {
+		return err
+	}
+	return util.DecodeWithCommonError(response, "WebhookSend")
+}
+
+// NewTextOption 文本消息，可通过 Mention 与 MentionMobile 提醒群成员
+func NewTextOption(content string) *WebhookSendTextOption {
+	option := &WebhookSendTextOption{MsgType: "text"}
+	option.Text.Content = content
+	return option
+}
+
+// Mention 通过userid提醒群中的成员，传入 MentionAll 表示提醒所有人
+func (o *WebhookSendTextOption) Mention(userIDs ...string) *WebhookSendTextOption {
+	o.Text.MentionedList = append(o.Text.MentionedList, userIDs...)
+	return o
+}
+
+// MentionMobile 通过手机号提醒群中的成员，传入 MentionAll 表示提醒所有人
+func (o *WebhookSendTextOption) MentionMobile(mobiles ...string) *WebhookSendTextOption {
+	o.Text.MentionedMobileList = append(o.Text.MentionedMobileList, mobiles...)
+	return o
+}
+
+// SendText 发送文本消息
+func (w *Webhook) SendText(option *WebhookSendTextOption) error {
+	if len(option.Text.Content) > MaxTextContentBytes {
+		return fmt.Errorf("text content is %d bytes, exceeds %d bytes", len(option.Text.Content), MaxTextContentBytes)
+	}
+	option.MsgType = "text"
+	return w.Send(option)
+}
+
+// NewMarkdownOption markdown消息
+func NewMarkdownOption(content string) *WebhookSendMarkdownOption {
+	option := &WebhookSendMarkdownOption{MsgType: "markdown"}
+	option.Markdown.Content = content
+	return option
+}
+
+// MarkdownMention 返回markdown内容中提醒成员的语法，markdown消息仅支持通过userid提醒
+func MarkdownMention(userID string) string {
+	return fmt.Sprintf("<@%s>", userID)
+}
+
+// SendMarkdown 发送markdown消息
+func (w *Webhook) SendMarkdown(option *WebhookSendMarkdownOption) error {
+	if len(option.Markdown.Content) > MaxMarkdownContentBytes {
+		return fmt.Errorf("markdown content is %d bytes, exceeds %d bytes", len(option.Markdown.Content), MaxMarkdownContentBytes)
+	}
+	option.MsgType = "markdown"
+	return w.Send(option)
+}
+
+// NewImageOption 图片消息，自动计算图片的base64编码与md5值
+func NewImageOption(image []byte) *WebhookSendImageOption {
+	sum := md5.Sum(image)
+	option := &WebhookSendImageOption{MsgType: "image"}
+	option.Image.Base64 = base64.StdEncoding.EncodeToString(image)
+	option.Image.MD5 = hex.EncodeToString(sum[:])
+	return option
+}
+
+// SendImage 发送图片消息，图片最大不能超过2M，支持JPG,PNG格式
+func (w *Webhook) SendImage(option *WebhookSendImageOption) error {
+	if size := base64.StdEncoding.DecodedLen(len(option.Image.Base64)); size > MaxImageBytes+2 {
+		return fmt.Errorf("image is about %d bytes, exceeds %d bytes", size, MaxImageBytes)
+	}
+	option.MsgType = "image"
+	return w.Send(option)
+}
+
+// NewNewsOption 图文消息
+func NewNewsOption(articles ...WebhookNewsArticle) *WebhookSendNewsOption {
+	option := &WebhookSendNewsOption{MsgType: "news"}
+	option.News.Articles = articles
+	return option
+}
+
+// SendNews 发送图文消息
+func (w *Webhook) SendNews(option *WebhookSendNewsOption) error {
+	if n := len(option.News.Articles); n < 1 || n > 8 {
+		return fmt.Errorf("news must contain 1 to 8 articles, got %d", n)
+	}
+	option.MsgType = "news"
+	return w.Send(option)
+}
+
+// NewFileOption 文件消息，mediaID通过 UploadMedia 获取
+func NewFileOption(mediaID string) *WebhookSendFileOption {
+	option := &WebhookSendFileOption{MsgType: "file"}
+	option.File.MediaID = mediaID
+	return option
+}
+
+// SendFile 发送文件消息
+func (w *Webhook) SendFile(option *WebhookSendFileOption) error {
+	option.MsgType = "file"
+	return w.Send(option)
+}
+
+// NewVoiceOption 语音消息，mediaID通过 UploadMedia 获取
+func NewVoiceOption(mediaID string) *WebhookSendVoiceOption {
+	option := &WebhookSendVoiceOption{MsgType: "voice"}
+	option.Voice.MediaID = mediaID
+	return option
+}
+
+// SendVoice 发送语音消息
+func (w *Webhook) SendVoice(option *WebhookSendVoiceOption) error {
+	option.MsgType = "voice"
+	return w.Send(option)
+}
+
+// SendTemplateCard 发送模版卡片消息
+func (w *Webhook) SendTemplateCard(option *WebHookSendTempNoticeOption) error {
+	option.MsgType = "template_card"
+	return w.Send(option)
+}
+
+// UploadMediaResponse 机器人上传文件响应
+type UploadMediaResponse struct {
+	util.CommonError
+	Type      string `json:"type"`
+	MediaID   string `json:"media_id"` // 媒体文件上传后获取的唯一标识，3天内有效
+	CreatedAt string `json:"created_at"`
+}
+
+// UploadMedia 上传文件，用于发送文件或语音消息
+// @see https://developer.work.weixin.qq.com/document/path/91770#文件上传接口
+// @mediaType 文件类型，分别有语音(voice)和普通文件(file)
+func (w *Webhook) UploadMedia(mediaType, filename string, reader io.Reader) (*UploadMediaResponse, error) {
+	byteData, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	var response []byte
+	if response, err = util.PostFileByStream("media", filename, fmt.Sprintf(webhookUploadMediaURL, w.key, mediaType), byteData); err != nil {
+		return nil, err
+	}
+	result := &UploadMediaResponse{}
+	err = util.DecodeWithError(response, result, "WebhookUploadMedia")
+	return result, err
+}
@@ -0,0 +1,69 @@
+package robot
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/h2non/gock.v1"
+)
+
+func mockWebhookSend(body string) {
+	gock.New("https://qyapi.weixin.qq.com").
+		Post("/cgi-bin/webhook/send").
+		MatchParam("key", "KEY").
+		BodyString(body).
+		Reply(200).
+		JSON(map[string]interface{}{"errcode": 0, "errmsg": "ok"})
+}
+
+func TestWebhookContentLimit(t *testing.T) {
+	defer gock.Off()
+	webhook := NewWebhook("KEY")
+	tests := []struct {
+		name    string
+		send    func() error
+		wantErr bool
+	}{
+		{"text at limit", func() error { return webhook.SendText(NewTextOption(strings.Repeat("a", MaxTextContentBytes))) }, false},
+		{"text over limit", func() error { return webhook.SendText(NewTextOption(strings.Repeat("a", MaxTextContentBytes+1))) }, true},
+		// 限制按字节计算，683个汉字为2049字节
+		{"text multibyte over limit", func() error { return webhook.SendText(NewTextOption(strings.Repeat("中", 683))) }, true},
+		{"markdown at limit", func() error {
+			return webhook.SendMarkdown(NewMarkdownOption(strings.Repeat("a", MaxMarkdownContentBytes)))
+		}, false},
+		{"markdown over limit", func() error {
+			return webhook.SendMarkdown(NewMarkdownOption(strings.Repeat("a", MaxMarkdownContentBytes+1)))
+		}, true},
+		{"markdown multibyte over limit", func() error {
+			return webhook.SendMarkdown(NewMarkdownOption(strings.Repeat("中", 1365) + "ab"))
+		}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !tt.wantErr {
+				mockWebhookSend("")
+			}
+			err := tt.send()
+			if tt.wantErr {
+				assert.ErrorContains(t, err, "exceeds")
+			} else {
+				assert.Nil(t, err)
+			}
+			assert.True(t, gock.IsDone())
+		})
+	}
+}
+
+func TestWebhookMention(t *testing.T) {
+	defer gock.Off()
+	webhook := NewWebhook("KEY")
+
+	mockWebhookSend(`"msgtype":"text","text":\{"content":"上线通知","mentioned_list":\["zhangsan","@all"\],"mentioned_mobile_list":\["13800000000"\]\}`)
+	assert.Nil(t, webhook.SendText(NewTextOption("上线通知").Mention("zhangsan").Mention(MentionAll).MentionMobile("13800000000")))
+
+	assert.Equal(t, "<@zhangsan>", MarkdownMention("zhangsan"))
+	mockWebhookSend(`"msgtype":"markdown","markdown":\{"content":"请 <@zhangsan> 处理"\}`)
+	assert.Nil(t, webhook.SendMarkdown(NewMarkdownOption("请 "+MarkdownMention("zhangsan")+" 处理")))
+	assert.True(t, gock.IsDone())
+}
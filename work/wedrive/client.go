@@ -0,0 +1,18 @@
+// Package wedrive 微盘，实现企业微信微盘相关接口：https://developer.work.weixin.qq.com/document/path/93654
+package wedrive
+
+import (
+	"github.com/silenceper/wechat/v2/work/context"
+)
+
+// Client 微盘接口实例
+type Client struct {
+	*context.Context
+}
+
+// NewClient 初始化实例
+func NewClient(ctx *context.Context) *Client {
+	return &Client{
+		ctx,
+	}
+}
@@ -0,0 +1,251 @@
+package wedrive
+
+import (
+	stdcontext "context"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/silenceper/wechat/v2/util"
+)
+
+const (
+	// fileListURL 获取文件列表
+	fileListURL = "https://qyapi.weixin.qq.com/cgi-bin/wedrive/file_list?access_token=%s"
+	// fileUploadURL 上传文件
+	fileUploadURL = "https://qyapi.weixin.qq.com/cgi-bin/wedrive/file_upload?access_token=%s"
+	// fileDownloadURL 下载文件
+	fileDownloadURL = "https://qyapi.weixin.qq.com/cgi-bin/wedrive/file_download?access_token=%s"
+	// fileCreateURL 新建文件夹/文档
+	fileCreateURL = "https://qyapi.weixin.qq.com/cgi-bin/wedrive/file_create?access_token=%s"
+	// fileRenameURL 重命名文件
+	fileRenameURL = "https://qyapi.weixin.qq.com/cgi-bin/wedrive/file_rename?access_token=%s"
+	// fileMoveURL 移动文件
+	fileMoveURL = "https://qyapi.weixin.qq.com/cgi-bin/wedrive/file_move?access_token=%s"
+	// fileDeleteURL 删除文件
+	fileDeleteURL = "https://qyapi.weixin.qq.com/cgi-bin/wedrive/file_delete?access_token=%s"
+	// fileACLAddURL 新增文件指定人
+	fileACLAddURL = "https://qyapi.weixin.qq.com/cgi-bin/wedrive/file_acl_add?access_token=%s"
+)
+
+// 文件类型
+const (
+	// FileTypeFolder 文件夹
+	FileTypeFolder = 1
+	// FileTypeFile 文件
+	FileTypeFile = 2
+	// FileTypeDoc 文档
+	FileTypeDoc = 3
+	// FileTypeSheet 表格
+	FileTypeSheet = 4
+)
+
+type (
+	// FileListRequest 获取文件列表请求
+	FileListRequest struct {
+		UserID   string `json:"userid,omitempty"`
+		SpaceID  string `json:"spaceid"`
+		FatherID string `json:"fatherid"`            // 当前目录的fileid,根目录时为空间spaceid
+		SortType int    `json:"sort_type,omitempty"` // 列表排序方式 1:名字升序 2:名字降序 3:大小升序 4:大小降序 5:修改时间升序 6:修改时间降序
+		Start    int    `json:"start"`               // 首次填0, 后续填上一次请求返回的next_start
+		Limit    int    `json:"limit"`               // 分批拉取最大文件数, 不超过1000
+	}
+	// FileListResponse 获取文件列表响应
+	FileListResponse struct {
+		util.CommonError
+		HasMore   bool `json:"has_more"`
+		NextStart int  `json:"next_start"`
+		FileList  struct {
+			Item []FileInfo `json:"item"`
+		} `json:"file_list"`
+	}
+	// FileInfo 文件信息
+	FileInfo struct {
+		FileID       string `json:"fileid"`
+		FileName     string `json:"file_name"`
+		SpaceID      string `json:"spaceid"`
+		FatherID     string `json:"fatherid"`
+		FileSize     int64  `json:"file_size"`
+		CTime        int64  `json:"ctime"`
+		MTime        int64  `json:"mtime"`
+		FileType     int    `json:"file_type"`   // 文件类型 1:文件夹 2:文件 3:文档 4:表格
+		FileStatus   int    `json:"file_status"` // 文件状态 1:正常 2:删除
+		CreateUserID string `json:"create_userid"`
+		UpdateUserID string `json:"update_userid"`
+		SHA          string `json:"sha"`
+		MD5          string `json:"md5"`
+		URL          string `json:"url"`
+	}
+)
+
+// FileList 获取文件列表
+// @see https://developer.work.weixin.qq.com/document/path/93657
+func (r *Client) FileList(req *FileListRequest) (*FileListResponse, error) {
+	result := &FileListResponse{}
+	err := r.post(fileListURL, req, result, "FileList")
+	return result, err
+}
+
+// RangeFileList 从req.Start开始逐页获取文件列表，每获取一页调用一次fn，fn返回错误时停止遍历并返回该错误
+func (r *Client) RangeFileList(req *FileListRequest, fn func(files []FileInfo) error) error {
+	pageReq := *req
+	for {
+		result, err := r.FileList(&pageReq)
+		if err != nil {
+			return err
+		}
+		if err = fn(result.FileList.Item); err != nil {
+			return err
+		}
+		if !result.HasMore {
+			return nil
+		}
+		pageReq.Start = result.NextStart
+	}
+}
+
+type (
+	// FileUploadRequest 上传文件请求，文件内容不超过10M
+	FileUploadRequest struct {
+		UserID            string `json:"userid,omitempty"`
+		SpaceID           string `json:"spaceid"`
+		FatherID          string `json:"fatherid"`
+		FileName          string `json:"file_name"`
+		FileBase64Content string `json:"file_base64_content"`
+	}
+	// FileIDResponse 返回文件id的响应
+	FileIDResponse struct {
+		util.CommonError
+		FileID string `json:"fileid"`
+	}
+)
+
+// FileUpload 上传文件，返回文件id；大于10M的文件请使用 UploadFromReader
+// @see https://developer.work.weixin.qq.com/document/path/93657
+func (r *Client) FileUpload(req *FileUploadRequest) (string, error) {
+	result := &FileIDResponse{}
+	err := r.post(fileUploadURL, req, result, "FileUpload")
+	return result.FileID, err
+}
+
+type (
+	// FileRequest 单个文件操作请求
+	FileRequest struct {
+		UserID string `json:"userid,omitempty"`
+		FileID string `json:"fileid"`
+	}
+	// FileDownloadResponse 下载文件响应
+	FileDownloadResponse struct {
+		util.CommonError
+		DownloadURL string `json:"download_url"`
+		CookieName  string `json:"cookie_name"`
+		CookieValue string `json:"cookie_value"`
+	}
+)
+
+// FileDownload 获取文件下载地址，下载时需要携带响应中的cookie
+// @see https://developer.work.weixin.qq.com/document/path/93657
+func (r *Client) FileDownload(req *FileRequest) (*FileDownloadResponse, error) {
+	result := &FileDownloadResponse{}
+	err := r.post(fileDownloadURL, req, result, "FileDownload")
+	return result, err
+}
+
+// DownloadTo 下载文件并将内容写入w，返回写入的字节数
+func (r *Client) DownloadTo(ctx stdcontext.Context, req *FileRequest, w io.Writer) (int64, error) {
+	download, err := r.FileDownload(req)
+	if err != nil {
+		return 0, err
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, download.DownloadURL, nil)
+	if err != nil {
+		return 0, err
+	}
+	request.AddCookie(&http.Cookie{Name: download.CookieName, Value: download.CookieValue})
+	response, err := util.DefaultHTTPClient.Do(request)
+	if err != nil {
+		return 0, err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("http get error : uri=%v , statusCode=%v", download.DownloadURL, response.StatusCode)
+	}
+	return io.Copy(w, response.Body)
+}
+
+type (
+	// FileCreateRequest 新建文件夹/文档请求
+	FileCreateRequest struct {
+		UserID   string `json:"userid,omitempty"`
+		SpaceID  string `json:"spaceid"`
+		FatherID string `json:"fatherid"`
+		FileType int    `json:"file_type"` // 文件类型 1:文件夹 3:文档 4:表格
+		FileName string `json:"file_name"`
+	}
+	// FileCreateResponse 新建文件夹/文档响应
+	FileCreateResponse struct {
+		util.CommonError
+		FileID string `json:"fileid"`
+		URL    string `json:"url"`
+	}
+)
+
+// FileCreate 新建文件夹/文档
+// @see https://developer.work.weixin.qq.com/document/path/93657
+func (r *Client) FileCreate(req *FileCreateRequest) (*FileCreateResponse, error) {
+	result := &FileCreateResponse{}
+	err := r.post(fileCreateURL, req, result, "FileCreate")
+	return result, err
+}
+
+// FileRenameRequest 重命名文件请求
+type FileRenameRequest struct {
+	UserID  string `json:"userid,omitempty"`
+	FileID  string `json:"fileid"`
+	NewName string `json:"new_name"`
+}
+
+// FileRename 重命名文件
+// @see https://developer.work.weixin.qq.com/document/path/93657
+func (r *Client) FileRename(req *FileRenameRequest) error {
+	return r.post(fileRenameURL, req, nil, "FileRename")
+}
+
+// FileMoveRequest 移动文件请求
+type FileMoveRequest struct {
+	UserID   string   `json:"userid,omitempty"`
+	FatherID string   `json:"fatherid"`          // 移动的目标目录
+	Replace  bool     `json:"replace,omitempty"` // 重名时是否覆盖, false时自动重命名
+	FileID   []string `json:"fileid"`
+}
+
+// FileMove 移动文件
+// @see https://developer.work.weixin.qq.com/document/path/93657
+func (r *Client) FileMove(req *FileMoveRequest) error {
+	return r.post(fileMoveURL, req, nil, "FileMove")
+}
+
+// FileDeleteRequest 删除文件请求
+type FileDeleteRequest struct {
+	UserID string   `json:"userid,omitempty"`
+	FileID []string `json:"fileid"`
+}
+
+// FileDelete 删除文件
+// @see https://developer.work.weixin.qq.com/document/path/93657
+func (r *Client) FileDelete(req *FileDeleteRequest) error {
+	return r.post(fileDeleteURL, req, nil, "FileDelete")
+}
+
+// FileACLRequest 新增文件指定人请求
+type FileACLRequest struct {
+	UserID   string     `json:"userid,omitempty"`
+	FileID   string     `json:"fileid"`
+	AuthInfo []AuthInfo `json:"auth_info"`
+}
+
+// FileACLAdd 新增文件指定人
+// @see https://developer.work.weixin.qq.com/document/path/93658
+func (r *Client) FileACLAdd(req *FileACLRequest) error {
+	return r.post(fileACLAddURL, req, nil, "FileACLAdd")
+}
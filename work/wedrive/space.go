@@ -0,0 +1,185 @@
+package wedrive
+
+import (
+	"fmt"
+
+	"github.com/silenceper/wechat/v2/util"
+)
+
+const (
+	// spaceCreateURL 新建空间
+	spaceCreateURL = "https://qyapi.weixin.qq.com/cgi-bin/wedrive/space_create?access_token=%s"
+	// spaceRenameURL 重命名空间
+	spaceRenameURL = "https://qyapi.weixin.qq.com/cgi-bin/wedrive/space_rename?access_token=%s"
+	// spaceDismissURL 解散空间
+	spaceDismissURL = "https://qyapi.weixin.qq.com/cgi-bin/wedrive/space_dismiss?access_token=%s"
+	// spaceInfoURL 获取空间信息
+	spaceInfoURL = "https://qyapi.weixin.qq.com/cgi-bin/wedrive/space_info?access_token=%s"
+	// spaceACLAddURL 添加成员/部门
+	spaceACLAddURL = "https://qyapi.weixin.qq.com/cgi-bin/wedrive/space_acl_add?access_token=%s"
+	// spaceACLDelURL 移除成员/部门
+	spaceACLDelURL = "https://qyapi.weixin.qq.com/cgi-bin/wedrive/space_acl_del?access_token=%s"
+	// spaceSettingURL 权限管理
+	spaceSettingURL = "https://qyapi.weixin.qq.com/cgi-bin/wedrive/space_setting?access_token=%s"
+	// spaceShareURL 获取邀请链接
+	spaceShareURL = "https://qyapi.weixin.qq.com/cgi-bin/wedrive/space_share?access_token=%s"
+)
+
+// 空间/文件权限成员类型
+const (
+	// AuthTypeUser 成员
+	AuthTypeUser = 1
+	// AuthTypeDepartment 部门
+	AuthTypeDepartment = 2
+)
+
+// AuthInfo 空间或文件的权限信息
+type AuthInfo struct {
+	Type         int    `json:"type"`                   // 成员类型 1:个人 2:部门
+	UserID       string `json:"userid,omitempty"`       // 成员userid,字段type为1时有效
+	DepartmentID int    `json:"departmentid,omitempty"` // 部门id,字段type为2时有效
+	Auth         int    `json:"auth,omitempty"`         // 成员权限 1:可下载 2:可预览 4:可管理（仅空间）7:管理员（仅空间）
+}
+
+type (
+	// SpaceCreateRequest 新建空间请求
+	SpaceCreateRequest struct {
+		UserID       string     `json:"userid,omitempty"`
+		SpaceName    string     `json:"space_name"`
+		AuthInfo     []AuthInfo `json:"auth_info,omitempty"`
+		SpaceSubType int        `json:"space_sub_type,omitempty"` // 空间类型, 0:普通 1:相册
+	}
+	// SpaceCreateResponse 新建空间响应
+	SpaceCreateResponse struct {
+		util.CommonError
+		SpaceID string `json:"spaceid"`
+	}
+)
+
+// SpaceCreate 新建空间，返回空间id
+// @see https://developer.work.weixin.qq.com/document/path/93655
+func (r *Client) SpaceCreate(req *SpaceCreateRequest) (string, error) {
+	result := &SpaceCreateResponse{}
+	err := r.post(spaceCreateURL, req, result, "SpaceCreate")
+	return result.SpaceID, err
+}
+
+// SpaceRenameRequest 重命名空间请求
+type SpaceRenameRequest struct {
+	UserID    string `json:"userid,omitempty"`
+	SpaceID   string `json:"spaceid"`
+	SpaceName string `json:"space_name"`
+}
+
+// SpaceRename 重命名空间
+// @see https://developer.work.weixin.qq.com/document/path/93655
+func (r *Client) SpaceRename(req *SpaceRenameRequest) error {
+	return r.post(spaceRenameURL, req, nil, "SpaceRename")
+}
+
+// SpaceRequest 空间操作请求
+type SpaceRequest struct {
+	UserID  string `json:"userid,omitempty"`
+	SpaceID string `json:"spaceid"`
+}
+
+// SpaceDismiss 解散空间
+// @see https://developer.work.weixin.qq.com/document/path/93655
+func (r *Client) SpaceDismiss(req *SpaceRequest) error {
+	return r.post(spaceDismissURL, req, nil, "SpaceDismiss")
+}
+
+type (
+	// SpaceInfoResponse 获取空间信息响应
+	SpaceInfoResponse struct {
+		util.CommonError
+		SpaceInfo SpaceInfo `json:"space_info"`
+	}
+	// SpaceInfo 空间信息
+	SpaceInfo struct {
+		SpaceID   string `json:"spaceid"`
+		SpaceName string `json:"space_name"`
+		AuthList  struct {
+			AuthInfo   []AuthInfo `json:"auth_info"`
+			QuitUserID []string   `json:"quit_userid"`
+		} `json:"auth_list"`
+		SpaceSubType int `json:"space_sub_type"`
+	}
+)
+
+// SpaceInfo 获取空间信息
+// @see https://developer.work.weixin.qq.com/document/path/93655
+func (r *Client) SpaceInfo(req *SpaceRequest) (*SpaceInfo, error) {
+	result := &SpaceInfoResponse{}
+	err := r.post(spaceInfoURL, req, result, "SpaceInfo")
+	return &result.SpaceInfo, err
+}
+
+// SpaceACLRequest 添加/移除空间成员请求
+type SpaceACLRequest struct {
+	UserID   string     `json:"userid,omitempty"`
+	SpaceID  string     `json:"spaceid"`
+	AuthInfo []AuthInfo `json:"auth_info"`
+}
+
+// SpaceACLAdd 添加成员/部门
+// @see https://developer.work.weixin.qq.com/document/path/93656
+func (r *Client) SpaceACLAdd(req *SpaceACLRequest) error {
+	return r.post(spaceACLAddURL, req, nil, "SpaceACLAdd")
+}
+
+// SpaceACLDel 移除成员/部门
+// @see https://developer.work.weixin.qq.com/document/path/93656
+func (r *Client) SpaceACLDel(req *SpaceACLRequest) error {
+	return r.post(spaceACLDelURL, req, nil, "SpaceACLDel")
+}
+
+// SpaceSettingRequest 空间权限管理请求，未填写的字段不会被修改
+type SpaceSettingRequest struct {
+	UserID                       string `json:"userid,omitempty"`
+	SpaceID                      string `json:"spaceid"`
+	EnableWatermark              *bool  `json:"enable_watermark,omitempty"`                  // 启用水印
+	AddMemberOnlyAdmin           *bool  `json:"add_member_only_admin,omitempty"`             // 仅管理员可增减空间成员
+	EnableShareURL               *bool  `json:"enable_share_url,omitempty"`                  // 启用成员邀请链接
+	ShareURLNoApprove            *bool  `json:"share_url_no_approve,omitempty"`              // 通过链接加入空间无需审批
+	ShareURLNoApproveDefaultAuth int    `json:"share_url_no_approve_default_auth,omitempty"` // 邀请链接默认权限
+}
+
+// SpaceSetting 空间权限管理
+// @see https://developer.work.weixin.qq.com/document/path/93656
+func (r *Client) SpaceSetting(req *SpaceSettingRequest) error {
+	return r.post(spaceSettingURL, req, nil, "SpaceSetting")
+}
+
+// SpaceShareResponse 获取邀请链接响应
+type SpaceShareResponse struct {
+	util.CommonError
+	SpaceShareURL string `json:"space_share_url"`
+}
+
+// SpaceShare 获取空间邀请链接
+// @see https://developer.work.weixin.qq.com/document/path/93656
+func (r *Client) SpaceShare(req *SpaceRequest) (string, error) {
+	result := &SpaceShareResponse{}
+	err := r.post(spaceShareURL, req, result, "SpaceShare")
+	return result.SpaceShareURL, err
+}
+
+// post 发起微盘接口请求，result为nil时仅解析通用错误
+func (r *Client) post(urlFormat string, req interface{}, result interface{}, apiName string) error {
+	var (
+		accessToken string
+		err         error
+	)
+	if accessToken, err = r.GetAccessToken(); err != nil {
+		return err
+	}
+	var response []byte
+	if response, err = util.PostJSON(fmt.Sprintf(urlFormat, accessToken), req); err != nil {
+		return err
+	}
+	if result == nil {
+		return util.DecodeWithCommonError(response, apiName)
+	}
+	return util.DecodeWithError(response, result, apiName)
+}
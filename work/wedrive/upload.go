@@ -0,0 +1,234 @@
+package wedrive
+
+import (
+	"crypto/sha1"
+	"encoding"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+
+	"github.com/silenceper/wechat/v2/util"
+)
+
+const (
+	// fileUploadInitURL 分块上传初始化
+	fileUploadInitURL = "https://qyapi.weixin.qq.com/cgi-bin/wedrive/file_upload_init?access_token=%s"
+	// fileUploadPartURL 分块上传文件
+	fileUploadPartURL = "https://qyapi.weixin.qq.com/cgi-bin/wedrive/file_upload_part?access_token=%s"
+	// fileUploadFinishURL 分块上传完成
+	fileUploadFinishURL = "https://qyapi.weixin.qq.com/cgi-bin/wedrive/file_upload_finish?access_token=%s"
+)
+
+const (
+	// UploadBlockSize 分块上传时每块的大小，除最后一块外每块固定为2M
+	UploadBlockSize = 2 << 20
+	// MaxSimpleUploadSize 使用 FileUpload 直接上传的文件大小上限
+	MaxSimpleUploadSize = 10 << 20
+)
+
+type (
+	// FileUploadInitRequest 分块上传初始化请求
+	FileUploadInitRequest struct {
+		UserID   string   `json:"userid,omitempty"`
+		SpaceID  string   `json:"spaceid"`
+		FatherID string   `json:"fatherid"`
+		FileName string   `json:"file_name"`
+		Size     int64    `json:"size"`
+		BlockSHA []string `json:"block_sha"` // 文件分块的sha值，最后一块为整个文件的sha，其他块为累积sha值
+	}
+	// FileUploadInitResponse 分块上传初始化响应
+	FileUploadInitResponse struct {
+		util.CommonError
+		HitExist  bool   `json:"hit_exist"` // 是否命中秒传，命中时无需上传分块
+		UploadKey string `json:"upload_key"`
+		FileID    string `json:"fileid"`
+	}
+	// FileUploadPartRequest 分块上传文件请求
+	FileUploadPartRequest struct {
+		UploadKey         string `json:"upload_key"`
+		Index             int    `json:"index"` // 分块索引，从1开始
+		FileBase64Content string `json:"file_base64_content"`
+	}
+	// FileUploadFinishRequest 分块上传完成请求
+	FileUploadFinishRequest struct {
+		UploadKey string `json:"upload_key"`
+	}
+)
+
+// FileUploadInit 分块上传初始化
+// @see https://developer.work.weixin.qq.com/document/path/98004
+func (r *Client) FileUploadInit(req *FileUploadInitRequest) (*FileUploadInitResponse, error) {
+	result := &FileUploadInitResponse{}
+	err := r.post(fileUploadInitURL, req, result, "FileUploadInit")
+	return result, err
+}
+
+// FileUploadPart 分块上传文件
+// @see https://developer.work.weixin.qq.com/document/path/98004
+func (r *Client) FileUploadPart(req *FileUploadPartRequest) error {
+	return r.post(fileUploadPartURL, req, nil, "FileUploadPart")
+}
+
+// FileUploadFinish 分块上传完成，返回文件id
+// @see https://developer.work.weixin.qq.com/document/path/98004
+func (r *Client) FileUploadFinish(req *FileUploadFinishRequest) (string, error) {
+	result := &FileIDResponse{}
+	err := r.post(fileUploadFinishURL, req, result, "FileUploadFinish")
+	return result.FileID, err
+}
+
+// UploadRequest 从Reader上传文件的请求
+type UploadRequest struct {
+	UserID   string
+	SpaceID  string
+	FatherID string // 上传的目标目录，根目录时为空间spaceid
+	FileName string
+}
+
+// UploadFromReader 读取reader中的全部内容上传到微盘，返回文件id
+// 不超过10M的文件直接上传，更大的文件使用分块上传；reader不是io.ReadSeeker时内容会先写入临时文件
+func (r *Client) UploadFromReader(req *UploadRequest, reader io.Reader) (string, error) {
+	head, err := io.ReadAll(io.LimitReader(reader, MaxSimpleUploadSize+1))
+	if err != nil {
+		return "", err
+	}
+	if len(head) <= MaxSimpleUploadSize {
+		return r.FileUpload(&FileUploadRequest{
+			UserID:            req.UserID,
+			SpaceID:           req.SpaceID,
+			FatherID:          req.FatherID,
+			FileName:          req.FileName,
+			FileBase64Content: base64.StdEncoding.EncodeToString(head),
+		})
+	}
+
+	var seeker io.ReadSeeker
+	if rs, ok := reader.(io.ReadSeeker); ok {
+		if _, err = rs.Seek(-int64(len(head)), io.SeekCurrent); err != nil {
+			return "", err
+		}
+		seeker = rs
+	} else {
+		tmp, err := os.CreateTemp("", "wedrive-upload-*")
+		if err != nil {
+			return "", err
+		}
+		defer func() {
+			_ = tmp.Close()
+			_ = os.Remove(tmp.Name())
+		}()
+		if _, err = tmp.Write(head); err != nil {
+			return "", err
+		}
+		if _, err = io.Copy(tmp, reader); err != nil {
+			return "", err
+		}
+		if _, err = tmp.Seek(0, io.SeekStart); err != nil {
+			return "", err
+		}
+		seeker = tmp
+	}
+	return r.uploadBlocks(req, seeker)
+}
+
+// uploadBlocks 分块上传reader当前位置之后的全部内容
+func (r *Client) uploadBlocks(req *UploadRequest, reader io.ReadSeeker) (string, error) {
+	start, err := reader.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return "", err
+	}
+	size, blockSHA, err := computeBlockSHA(reader)
+	if err != nil {
+		return "", err
+	}
+	initResult, err := r.FileUploadInit(&FileUploadInitRequest{
+		UserID:   req.UserID,
+		SpaceID:  req.SpaceID,
+		FatherID: req.FatherID,
+		FileName: req.FileName,
+		Size:     size,
+		BlockSHA: blockSHA,
+	})
+	if err != nil {
+		return "", err
+	}
+	if initResult.HitExist {
+		return initResult.FileID, nil
+	}
+
+	if _, err = reader.Seek(start, io.SeekStart); err != nil {
+		return "", err
+	}
+	block := make([]byte, UploadBlockSize)
+	for index := 1; index <= len(blockSHA); index++ {
+		n, err := io.ReadFull(reader, block)
+		if err != nil && err != io.ErrUnexpectedEOF {
+			return "", err
+		}
+		if err = r.FileUploadPart(&FileUploadPartRequest{
+			UploadKey:         initResult.UploadKey,
+			Index:             index,
+			FileBase64Content: base64.StdEncoding.EncodeToString(block[:n]),
+		}); err != nil {
+			return "", fmt.Errorf("upload block %d: %w", index, err)
+		}
+	}
+	return r.FileUploadFinish(&FileUploadFinishRequest{UploadKey: initResult.UploadKey})
+}
+
+// computeBlockSHA 按2M分块计算文件大小与每块的sha值
+// 除最后一块外，每块的sha值为截止到该块末尾的sha1中间状态（5个字按小端序输出），最后一块为整个文件的sha1
+func computeBlockSHA(reader io.Reader) (int64, []string, error) {
+	var (
+		size     int64
+		blockSHA []string
+	)
+	digest := sha1.New()
+	block := make([]byte, UploadBlockSize)
+	for {
+		n, err := io.ReadFull(reader, block)
+		if n > 0 {
+			size += int64(n)
+			digest.Write(block[:n])
+			state, err := sha1State(digest)
+			if err != nil {
+				return 0, nil, err
+			}
+			blockSHA = append(blockSHA, state)
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			break
+		}
+		if err != nil {
+			return 0, nil, err
+		}
+	}
+	if len(blockSHA) == 0 {
+		return 0, nil, fmt.Errorf("upload content is empty")
+	}
+	blockSHA[len(blockSHA)-1] = hex.EncodeToString(digest.Sum(nil))
+	return size, blockSHA, nil
+}
+
+// sha1State 导出sha1的中间状态，要求已写入的数据长度为64字节的整数倍
+func sha1State(h hash.Hash) (string, error) {
+	marshaler, ok := h.(encoding.BinaryMarshaler)
+	if !ok {
+		return "", fmt.Errorf("sha1 state is not exportable")
+	}
+	// crypto/sha1 的序列化格式为 "sha\x01" + 5个大端序的字 + 未处理的数据 + 长度
+	data, err := marshaler.MarshalBinary()
+	if err != nil {
+		return "", err
+	}
+	state := make([]byte, 20)
+	for i := 0; i < 5; i++ {
+		word := binary.BigEndian.Uint32(data[4+4*i:])
+		binary.LittleEndian.PutUint32(state[4*i:], word)
+	}
+	return hex.EncodeToString(state), nil
+}
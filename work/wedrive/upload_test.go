@@ -0,0 +1,86 @@
+package wedrive
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/h2non/gock.v1"
+
+	"github.com/northseadl/wechat/v2/internal/worktest"
+)
+
+func mockWedrive(path string, body string, reply map[string]interface{}) {
+	reply["errcode"] = 0
+	gock.New("https://qyapi.weixin.qq.com").
+		Post("/cgi-bin/wedrive/" + path).
+		BodyString(body).
+		Reply(200).
+		JSON(reply)
+}
+
+func TestComputeBlockSHA(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  []byte
+		blockSHA []string
+	}{
+		{"single block", []byte("abc"), []string{"a9993e364706816aba3e25717850c26c9cd0d89d"}},
+		// 期望值由独立的sha1实现计算：第一块为处理完2M个a后的中间状态，按小端序输出5个字
+		{
+			"two blocks",
+			append(bytes.Repeat([]byte("a"), UploadBlockSize), "wedrive"...),
+			[]string{"d7a690d1b94baf0819425a2fd7530988754204d2", "ee95c83d68bdc1f4d46a050211e0d572a2ffad87"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			size, blockSHA, err := computeBlockSHA(bytes.NewReader(tt.content))
+			assert.Nil(t, err)
+			assert.Equal(t, int64(len(tt.content)), size)
+			assert.Equal(t, tt.blockSHA, blockSHA)
+		})
+	}
+
+	_, _, err := computeBlockSHA(bytes.NewReader(nil))
+	assert.NotNil(t, err)
+}
+
+func TestUploadFromReader(t *testing.T) {
+	defer gock.Off()
+	client := NewClient(worktest.NewContext())
+	req := &UploadRequest{SpaceID: "SPACEID", FatherID: "FATHERID", FileName: "a.txt"}
+
+	// 不超过10M的文件直接上传
+	mockWedrive("file_upload$", `"spaceid":"SPACEID","fatherid":"FATHERID","file_name":"a.txt","file_base64_content":"aGVsbG8="`,
+		map[string]interface{}{"fileid": "FILE1"})
+	fileID, err := client.UploadFromReader(req, strings.NewReader("hello"))
+	assert.Nil(t, err)
+	assert.Equal(t, "FILE1", fileID)
+	assert.True(t, gock.IsDone())
+
+	// 超过10M的文件分为6块上传，最后一块只有1个字节，reader不是io.ReadSeeker时先写入临时文件
+	content := bytes.Repeat([]byte("a"), MaxSimpleUploadSize+1)
+	mockWedrive("file_upload_init", fmt.Sprintf(`"file_name":"a.txt","size":%d,"block_sha":\[("[0-9a-f]{40}",){5}"%x"\]`, len(content), sha1.Sum(content)),
+		map[string]interface{}{"upload_key": "KEY"})
+	for index := 1; index < 6; index++ {
+		mockWedrive("file_upload_part", fmt.Sprintf(`"upload_key":"KEY","index":%d,"file_base64_content":"YWFh`, index), map[string]interface{}{})
+	}
+	mockWedrive("file_upload_part", `"upload_key":"KEY","index":6,"file_base64_content":"YQ=="`, map[string]interface{}{})
+	mockWedrive("file_upload_finish", `"upload_key":"KEY"`, map[string]interface{}{"fileid": "FILE2"})
+	fileID, err = client.UploadFromReader(req, io.MultiReader(bytes.NewReader(content)))
+	assert.Nil(t, err)
+	assert.Equal(t, "FILE2", fileID)
+	assert.True(t, gock.IsDone())
+
+	// 命中秒传时不上传分块
+	mockWedrive("file_upload_init", `"size":10485761`, map[string]interface{}{"hit_exist": true, "fileid": "FILE3"})
+	fileID, err = client.UploadFromReader(req, bytes.NewReader(content))
+	assert.Nil(t, err)
+	assert.Equal(t, "FILE3", fileID)
+	assert.True(t, gock.IsDone())
+}
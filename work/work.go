@@ -1,20 +1,28 @@
+// Package work 企业微信
+//
+// 企业微信的各业务包（work/addresslist、work/kf等）均从本模块导入；
+// util、cache、credential、work/config、work/context等基础包与公众号、小程序等模块共用，继续使用上游路径以保持类型一致
 package work
 
 import (
+	"github.com/northseadl/wechat/v2/work/addresslist"
+	"github.com/northseadl/wechat/v2/work/agent"
+	"github.com/northseadl/wechat/v2/work/appchat"
+	"github.com/northseadl/wechat/v2/work/checkin"
+	"github.com/northseadl/wechat/v2/work/export"
+	"github.com/northseadl/wechat/v2/work/externalcontact"
+	"github.com/northseadl/wechat/v2/work/invoice"
+	"github.com/northseadl/wechat/v2/work/kf"
+	"github.com/northseadl/wechat/v2/work/material"
+	"github.com/northseadl/wechat/v2/work/meeting"
+	"github.com/northseadl/wechat/v2/work/message"
+	"github.com/northseadl/wechat/v2/work/msgaudit"
+	"github.com/northseadl/wechat/v2/work/oauth"
+	"github.com/northseadl/wechat/v2/work/robot"
+	"github.com/northseadl/wechat/v2/work/wedrive"
 	"github.com/silenceper/wechat/v2/credential"
-	"github.com/silenceper/wechat/v2/work/addresslist"
-	"github.com/silenceper/wechat/v2/work/appchat"
-	"github.com/silenceper/wechat/v2/work/checkin"
 	"github.com/silenceper/wechat/v2/work/config"
 	"github.com/silenceper/wechat/v2/work/context"
-	"github.com/silenceper/wechat/v2/work/externalcontact"
-	"github.com/silenceper/wechat/v2/work/invoice"
-	"github.com/silenceper/wechat/v2/work/kf"
-	"github.com/silenceper/wechat/v2/work/material"
-	"github.com/silenceper/wechat/v2/work/message"
-	"github.com/silenceper/wechat/v2/work/msgaudit"
-	"github.com/silenceper/wechat/v2/work/oauth"
-	"github.com/silenceper/wechat/v2/work/robot"
 )
 
 // Work 企业微信
@@ -91,3 +99,23 @@ func (wk *Work) GetInvoice() *invoice.Client {
 func (wk *Work) GetCheckin() *checkin.Client {
 	return checkin.NewClient(wk.ctx)
 }
+
+// GetMeeting 获取会议管理接口实例
+func (wk *Work) GetMeeting() *meeting.Client {
+	return meeting.NewClient(wk.ctx)
+}
+
+// GetAgent 获取应用管理接口实例
+func (wk *Work) GetAgent() *agent.Client {
+	return agent.NewClient(wk.ctx)
+}
+
+// GetExport 获取异步导出接口实例
+func (wk *Work) GetExport() *export.Client {
+	return export.NewClient(wk.ctx)
+}
+
+// GetWeDrive 获取微盘接口实例
+func (wk *Work) GetWeDrive() *wedrive.Client {
+	return wedrive.NewClient(wk.ctx)
+}